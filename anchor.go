@@ -0,0 +1,96 @@
+package goglicko
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Anchor holds the mean of a rating pool in place against drift. Players
+// leaving with rating points they won, or new players joining below or above
+// the pool, move the mean over time; the base rating of a System only decides
+// where newcomers start.
+//
+// After every period, Apply measures how far the mean of the pool, or of a
+// reference group of players, is from Target and shifts every rating towards
+// it. Shifting everyone by the same amount leaves every expected score as it
+// was.
+type Anchor struct {
+	Target float64 // Mean to hold the pool or reference group at
+
+	// Players whose mean is held at Target, e.g. a stable group of
+	// established players. Empty means the whole pool.
+	Reference []string
+
+	// Share of the drift corrected each period, between 0 and 1: 1 corrects
+	// it in full, and 0 turns the anchor off, only measuring the drift.
+	Rate float64
+
+	// Largest shift applied in one period. Zero means no limit.
+	MaxShift float64
+
+	// Whether the mean is weighted by confidence, see Pool.
+	Weighted bool
+
+	// Every adjustment applied, oldest first.
+	Log []Adjustment
+}
+
+// Adjustment records one correction made by an Anchor.
+type Adjustment struct {
+	Period  int64
+	Time    time.Time
+	Players int     // Number of ratings the mean was measured over
+	Mean    float64 // Mean before the correction
+	Target  float64
+	Shift   float64 // Points added to every rating
+}
+
+// Apply measures the drift of ratings at the end of p and shifts every rating
+// to correct it, recording the adjustment in the Log. Ratings with a History
+// record the shifted rating at the end of p, amending the snapshot RatePeriod
+// recorded then rather than adding a second one. If none of the measured
+// players are rated, nothing is shifted.
+func (a *Anchor) Apply(ratings map[string]*Rating, p *Period) (Adjustment, error) {
+	if a.Rate < 0 || a.Rate > 1 {
+		return Adjustment{}, fmt.Errorf("Anchor rate must be between 0 and 1, was %v", a.Rate)
+	}
+	if a.MaxShift < 0 {
+		return Adjustment{}, fmt.Errorf("Anchor max shift can't be negative, was %v", a.MaxShift)
+	}
+
+	measured := ratings
+	if len(a.Reference) > 0 {
+		measured = make(map[string]*Rating)
+		for _, player := range a.Reference {
+			if r, ok := ratings[player]; ok {
+				measured[player] = r
+			}
+		}
+	}
+
+	adj := Adjustment{Period: p.ID, Time: p.End, Players: len(measured), Target: a.Target}
+	if len(measured) > 0 {
+		adj.Mean = NewPoolFromMap(measured, a.Weighted).Mean()
+		adj.Shift = (a.Target - adj.Mean) * a.Rate
+		if a.MaxShift > 0 {
+			adj.Shift = math.Max(-a.MaxShift, math.Min(a.MaxShift, adj.Shift))
+		}
+	}
+
+	if adj.Shift != 0 {
+		for _, r := range ratings {
+			r.rating += adj.Shift
+			if h := r.history; h != nil {
+				if n := len(h.snapshots); n > 0 && h.snapshots[n-1].Time.Equal(p.End) {
+					h.snapshots[n-1].Rating = r.rating
+				} else {
+					h.Record(r.Snapshot(p.End, 0))
+				}
+			}
+		}
+	}
+
+	a.Log = append(a.Log, adj)
+	return adj, nil
+}
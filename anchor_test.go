@@ -0,0 +1,120 @@
+package goglicko
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAnchorPool(t *testing.T) {
+	sys := NewDefaultSystem()
+	ratings := map[string]*Rating{
+		"a": NewRating(1400, 50, DefaultVol, sys),
+		"b": NewRating(1500, 50, DefaultVol, sys),
+		"c": NewRating(1660, 50, DefaultVol, sys),
+	}
+	before := ExpectedScore(ratings["a"], ratings["c"])
+	end := time.Date(2017, time.January, 8, 0, 0, 0, 0, time.UTC)
+
+	a := &Anchor{Target: 1500, Rate: 1}
+	adj, err := a.Apply(ratings, &Period{ID: 3, End: end})
+	if err != nil {
+		t.Fatalf("Error while anchoring: %v", err)
+	}
+
+	expected := Adjustment{Period: 3, Time: end, Players: 3, Mean: 1520, Target: 1500, Shift: -20}
+	if adj != expected {
+		t.Errorf("Adjustment %+v, expected %+v", adj, expected)
+	}
+	if len(a.Log) != 1 || a.Log[0] != adj {
+		t.Errorf("Adjustment not logged: %+v", a.Log)
+	}
+	if r, _, _ := ratings["b"].GetValues(); r != 1480 {
+		t.Errorf("Rating of b shifted to %v", r)
+	}
+	if after := ExpectedScore(ratings["a"], ratings["c"]); !floatsMostlyEqual(before, after, 1e-12) {
+		t.Errorf("Expected score changed from %v to %v", before, after)
+	}
+	if mean := NewPoolFromMap(ratings, false).Mean(); !floatsMostlyEqual(mean, 1500, 1e-9) {
+		t.Errorf("Pool mean %v after anchoring", mean)
+	}
+}
+
+func TestAnchorReference(t *testing.T) {
+	sys := NewDefaultSystem()
+	ratings := map[string]*Rating{
+		"a":   NewRating(1550, 50, DefaultVol, sys),
+		"b":   NewRating(1650, 50, DefaultVol, sys),
+		"new": NewRating(1000, 350, DefaultVol, sys),
+	}
+	h := NewHistory()
+	ratings["new"].SetHistory(h)
+
+	a := &Anchor{Target: 1500, Reference: []string{"a", "b", "gone"}, Rate: 0.5, MaxShift: 40}
+	adj, err := a.Apply(ratings, &Period{ID: 1})
+	if err != nil {
+		t.Fatalf("Error while anchoring: %v", err)
+	}
+	if adj.Players != 2 || adj.Mean != 1600 || adj.Shift != -40 {
+		t.Errorf("Adjustment %+v", adj)
+	}
+	if r, _, _ := ratings["new"].GetValues(); r != 960 {
+		t.Errorf("Rating outside the reference group shifted to %v", r)
+	}
+	if h.Len() != 1 || h.Snapshots()[0].Rating != 960 {
+		t.Errorf("Shift not recorded in history: %+v", h.Snapshots())
+	}
+
+	adj, err = (&Anchor{Target: 1500, Reference: []string{"gone"}, Rate: 1}).Apply(ratings, &Period{ID: 2})
+	if err != nil || adj.Players != 0 || adj.Shift != 0 {
+		t.Errorf("Adjustment without reference players %+v, %v", adj, err)
+	}
+}
+
+func TestAnchorOff(t *testing.T) {
+	ratings := map[string]*Rating{"a": NewRating(1600, 50, DefaultVol, NewDefaultSystem())}
+
+	a := &Anchor{Target: 1500}
+	adj, err := a.Apply(ratings, &Period{ID: 1})
+	if err != nil {
+		t.Fatalf("Error while anchoring: %v", err)
+	}
+	if adj.Mean != 1600 || adj.Shift != 0 || len(a.Log) != 1 {
+		t.Errorf("Adjustment %+v with a zero rate, expected the drift measured only", adj)
+	}
+	if r, _, _ := ratings["a"].GetValues(); r != 1600 {
+		t.Errorf("Zero rate shifted the rating to %v", r)
+	}
+}
+
+func TestAnchorAmendsHistory(t *testing.T) {
+	sys := NewDefaultSystem()
+	start := time.Date(2017, time.January, 1, 0, 0, 0, 0, time.UTC)
+	p := &Period{ID: 1, Start: start, End: start.Add(7 * 24 * time.Hour), Games: []*Game{
+		{Player1: "a", Player2: "b", Result: Win, Played: start, Weight: 1},
+	}}
+	ratings := map[string]*Rating{"a": sys.NewRating(), "b": sys.NewRating()}
+	h := NewHistory()
+	ratings["a"].SetHistory(h)
+	if err := RatePeriod(ratings, p, sys); err != nil {
+		t.Fatalf("Error while rating: %v", err)
+	}
+
+	if _, err := (&Anchor{Target: 1400, Rate: 1}).Apply(ratings, p); err != nil {
+		t.Fatalf("Error while anchoring: %v", err)
+	}
+	snaps := h.Snapshots()
+	if len(snaps) != 1 {
+		t.Fatalf("History %+v, expected the period's snapshot amended", snaps)
+	}
+	if r, _, _ := ratings["a"].GetValues(); snaps[0].Rating != r || snaps[0].Games != 1 {
+		t.Errorf("Snapshot %+v, expected rating %v over 1 game", snaps[0], r)
+	}
+}
+
+func TestAnchorErrors(t *testing.T) {
+	for _, a := range []*Anchor{{Rate: -0.1}, {Rate: 1.5}, {MaxShift: -1}} {
+		if _, err := a.Apply(map[string]*Rating{}, &Period{}); err == nil {
+			t.Errorf("Expected an error for %+v", a)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/clavoie/goglicko"
+)
+
+// explain shows the work behind one player's update for a rating period.
+func explain(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("explain", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: goglicko explain -ratings file [flags] player [opponent result ...]")
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "Prints every step of the Glicko 2 update of a player for one rating period.")
+		fmt.Fprintln(stderr, "The player's games are given as opponent and result argument pairs, or are")
+		fmt.Fprintln(stderr, "the games they played in the -games file. Ratings are those from before the")
+		fmt.Fprintln(stderr, "period; players missing from the rating list start as newcomers.")
+		fmt.Fprintln(stderr)
+		fs.PrintDefaults()
+	}
+	sf := addSystemFlags(fs)
+	gf := addGameFlags(fs)
+	ratingsFile := fs.String("ratings", "", "CSV rating list `file` from before the period, as written by goglicko rate")
+	gamesFile := fs.String("games", "", "game `file` of the rating period")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *ratingsFile == "" || fs.NArg() == 0 {
+		fs.Usage()
+		return fmt.Errorf("-ratings and a player are required")
+	}
+
+	sys, err := sf.system(fs)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(*ratingsFile)
+	if err != nil {
+		return err
+	}
+	ratings, err := goglicko.ReadRatingsCSV(f, sys)
+	f.Close()
+	if err != nil {
+		return fmt.Errorf("%v: %v", *ratingsFile, err)
+	}
+	rating := func(player string) *goglicko.Rating {
+		if r, ok := ratings[player]; ok {
+			return r
+		}
+		return sys.NewRating()
+	}
+
+	// A game's advantage shifts the opponent, as when rating the period.
+	shifted := func(r *goglicko.Rating, points float64) *goglicko.Rating {
+		rating, deviation, volatility := r.GetValues()
+		return goglicko.NewRating(rating+points, deviation, volatility, r.GetSystem())
+	}
+
+	player := fs.Arg(0)
+	var names []string
+	var opponents []*goglicko.Rating
+	var results []goglicko.Result
+	var weights []float64
+	if *gamesFile != "" {
+		games, err := gf.readGames([]string{*gamesFile}, nil, stderr)
+		if err != nil {
+			return err
+		}
+		for _, g := range games {
+			switch player {
+			case g.Player1:
+				names = append(names, g.Player2)
+				opponents = append(opponents, shifted(rating(g.Player2), -g.Advantage))
+				results = append(results, g.Result)
+			case g.Player2:
+				names = append(names, g.Player1)
+				opponents = append(opponents, shifted(rating(g.Player1), g.Advantage))
+				results = append(results, g.Result.Opposite())
+			default:
+				continue
+			}
+			weights = append(weights, g.Weight)
+		}
+	}
+
+	rest := fs.Args()[1:]
+	if len(rest)%2 != 0 {
+		return fmt.Errorf("opponents and results must be given in pairs")
+	}
+	for i := 0; i < len(rest); i += 2 {
+		res, err := goglicko.ParseResult(rest[i+1])
+		if err != nil {
+			return err
+		}
+		names = append(names, rest[i])
+		opponents = append(opponents, rating(rest[i]))
+		results = append(results, res)
+		weights = append(weights, 1)
+	}
+
+	trace, err := rating(player).ExplainWeighted(opponents, results, weights)
+	if err != nil {
+		return err
+	}
+	return writeTrace(stdout, player, names, trace)
+}
+
+// writeTrace prints a trace step by step.
+func writeTrace(w io.Writer, player string, opponents []string, t *goglicko.Trace) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "Step 1: initial values of %v\n", player)
+	fmt.Fprintf(tw, "  rating %.3f, deviation %.3f, volatility %.6f, tau %v\n",
+		t.Rating, t.Deviation, t.Volatility, t.Tau)
+
+	fmt.Fprintln(tw, "Step 2: conversion to the Glicko 2 scale")
+	fmt.Fprintf(tw, "  mu %.4f, phi %.4f, sigma %.6f\n", t.Mu, t.Phi, t.Sigma)
+	if len(t.Opponents) == 0 {
+		fmt.Fprintln(tw, "  no games: only the deviation changes, in step 6")
+	} else {
+		fmt.Fprintln(tw, "  opponent\tmu_j\tphi_j\tresult\tweight\tg(phi_j)\tE")
+		for i, o := range t.Opponents {
+			fmt.Fprintf(tw, "  %v\t%.4f\t%.4f\t%v\t%v\t%.4f\t%.4f\n",
+				opponents[i], o.Mu, o.Phi, o.Result, o.Weight, o.G, o.E)
+		}
+		fmt.Fprintf(tw, "Step 3: estimated variance v = %.4f\n", t.Variance)
+		fmt.Fprintf(tw, "Step 4: estimated improvement Delta = %.4f\n", t.Delta)
+		fmt.Fprintf(tw, "Step 5: new volatility sigma' = %.6f after %v iterations\n",
+			t.NewVolatility, t.Iterations)
+		if !t.Converged {
+			fmt.Fprintln(tw, "  the solver did not converge, the last estimate is used")
+		}
+		for i, sigma := range t.VolatilityPath {
+			fmt.Fprintf(tw, "  iteration %v: sigma = %.6f\n", i+1, sigma)
+		}
+	}
+	fmt.Fprintf(tw, "Step 6: pre-rating period deviation phi* = %.4f\n", t.PrePeriodPhi)
+	fmt.Fprintf(tw, "Step 7: new mu' = %.4f, phi' = %.4f\n", t.NewMu, t.NewPhi)
+	fmt.Fprintln(tw, "Step 8: conversion back to the Glicko 1 scale")
+	fmt.Fprintf(tw, "  rating %.3f (%+.3f), deviation %.3f (%+.3f), volatility %.6f\n",
+		t.NewRating, t.NewRating-t.Rating, t.NewDeviation, t.NewDeviation-t.Deviation, t.NewVolatility)
+	if t.Capped {
+		fmt.Fprintf(tw, "  deviation capped at the base deviation, down from %.3f\n", t.PreClampDeviation)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/clavoie/goglicko"
+)
+
+func TestExplain(t *testing.T) {
+	ratings := writeFile(t, "ratings.csv", "player,rating,deviation,volatility\n"+
+		"pl,1500,200,0.06\no1,1400,30,0.06\no2,1550,100,0.06\no3,1700,300,0.06\n")
+	games := writeFile(t, "games.csv", "pl,o1,1,2017-03-01\no2,pl,1,2017-03-02\nother,o3,1,2017-03-02\n")
+
+	var stdout, stderr bytes.Buffer
+	err := explain([]string{"-ratings", ratings, "-games", games, "pl", "o3", "0"}, &stdout, &stderr)
+	if err != nil {
+		t.Fatalf("Error while explaining: %v\n%v", err, stderr.String())
+	}
+
+	out := stdout.String()
+	for _, exp := range []string{"v = 1.779", "Delta = -0.483", "rating 1464.0", "deviation 151.5"} {
+		if !strings.Contains(out, exp) {
+			t.Errorf("Output doesn't contain %q:\n%v", exp, out)
+		}
+	}
+	if strings.Contains(out, "other") {
+		t.Errorf("Output contains a game pl didn't play:\n%v", out)
+	}
+}
+
+func TestExplainWeightedGames(t *testing.T) {
+	ratings := writeFile(t, "ratings.csv", "player,rating,deviation,volatility\n"+
+		"pl,1500,200,0.06\no1,1400,30,0.06\no2,1550,100,0.06\n")
+	games := writeFile(t, "games.jsonl",
+		`{"player1": "pl", "player2": "o1", "result": 1, "played": "2017-03-01T00:00:00Z", "weight": 2, "advantage": 50}`+"\n"+
+			`{"player1": "o2", "player2": "pl", "result": 1, "played": "2017-03-02T00:00:00Z", "weight": 0.5, "advantage": 35}`+"\n")
+
+	var stdout, stderr bytes.Buffer
+	if err := explain([]string{"-ratings", ratings, "-games", games, "pl"}, &stdout, &stderr); err != nil {
+		t.Fatalf("Error while explaining: %v\n%v", err, stderr.String())
+	}
+
+	// The explained update must be the one rating the period makes.
+	sys := goglicko.NewDefaultSystem()
+	played := time.Date(2017, 3, 1, 0, 0, 0, 0, time.UTC)
+	p := &goglicko.Period{End: played.Add(7 * 24 * time.Hour), Games: []*goglicko.Game{
+		{Player1: "pl", Player2: "o1", Result: goglicko.Win, Played: played, Weight: 2, Advantage: 50},
+		{Player1: "o2", Player2: "pl", Result: goglicko.Win, Played: played.Add(24 * time.Hour), Weight: 0.5, Advantage: 35},
+	}}
+	rated := map[string]*goglicko.Rating{
+		"pl": goglicko.NewRating(1500, 200, goglicko.DefaultVol, sys),
+		"o1": goglicko.NewRating(1400, 30, goglicko.DefaultVol, sys),
+		"o2": goglicko.NewRating(1550, 100, goglicko.DefaultVol, sys),
+	}
+	if err := goglicko.RatePeriod(rated, p, sys); err != nil {
+		t.Fatalf("Error while rating: %v", err)
+	}
+	rating, deviation, _ := rated["pl"].GetValues()
+	out := stdout.String()
+	for _, exp := range []string{fmt.Sprintf("rating %.3f", rating), fmt.Sprintf("deviation %.3f", deviation)} {
+		if !strings.Contains(out, exp) {
+			t.Errorf("Output doesn't contain %q:\n%v", exp, out)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+// Command goglicko rates players from game files with the Glicko 2 system.
+//
+// Usage:
+//
+//	goglicko <command> [flags] [files]
+//
+// The commands are:
+//
+//	rate     rate the games of a CSV or JSON Lines file and print the ratings
+//	predict  forecast pairings from a rating list
+//	explain  show each step of the update of a player's rating
+//
+// Run goglicko <command> -h for the flags of a command.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// A command reads its flags from args and writes its output to stdout.
+type command func(args []string, stdout, stderr io.Writer) error
+
+var commands = map[string]command{
+	"rate":    rate,
+	"predict": predict,
+	"explain": explain,
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: goglicko <command> [flags] [files]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	fmt.Fprintln(w, "  rate     rate the games of a CSV or JSON Lines file and print the ratings")
+	fmt.Fprintln(w, "  predict  forecast pairings from a rating list")
+	fmt.Fprintln(w, "  explain  show each step of the update of a player's rating")
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage(os.Stderr)
+		os.Exit(2)
+	}
+
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "goglicko: unknown command %q\n\n", os.Args[1])
+		usage(os.Stderr)
+		os.Exit(2)
+	}
+
+	if err := cmd(os.Args[2:], os.Stdout, os.Stderr); err != nil {
+		fmt.Fprintf(os.Stderr, "goglicko %v: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/clavoie/goglicko"
+)
+
+// readPairings reads one pairing per CSV record: the first player, then their
+// opponent.
+func readPairings(r io.Reader) ([][2]string, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	cr.FieldsPerRecord = 2
+	var pairings [][2]string
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return pairings, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		pairings = append(pairings, [2]string{strings.TrimSpace(record[0]), strings.TrimSpace(record[1])})
+	}
+}
+
+// predict forecasts pairings from a rating list, as written by rate.
+func predict(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("predict", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: goglicko predict -ratings file [flags] [player opponent ...]")
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "Prints the win, draw and loss probabilities of each pairing, and the rating")
+		fmt.Fprintln(stderr, "changes each result would bring. Pairings are given as pairs of arguments,")
+		fmt.Fprintln(stderr, "or read as player,opponent CSV records from -pairings or standard input.")
+		fmt.Fprintln(stderr)
+		fs.PrintDefaults()
+	}
+	sf := addSystemFlags(fs)
+	ratingsFile := fs.String("ratings", "", "CSV rating list `file`, as written by goglicko rate")
+	pairingsFile := fs.String("pairings", "", "CSV `file` of player,opponent pairings")
+	drawRate := fs.Float64("draw-rate", 0, "chance of a draw between equally matched players")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *ratingsFile == "" {
+		return fmt.Errorf("-ratings is required")
+	}
+
+	sys, err := sf.system(fs)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(*ratingsFile)
+	if err != nil {
+		return err
+	}
+	ratings, err := goglicko.ReadRatingsCSV(bufio.NewReader(f), sys)
+	f.Close()
+	if err != nil {
+		return fmt.Errorf("%v: %v", *ratingsFile, err)
+	}
+
+	var pairings [][2]string
+	switch {
+	case fs.NArg() > 0:
+		if fs.NArg()%2 != 0 {
+			return fmt.Errorf("players must be given in pairs, got %v", fs.NArg())
+		}
+		for i := 0; i < fs.NArg(); i += 2 {
+			pairings = append(pairings, [2]string{fs.Arg(i), fs.Arg(i + 1)})
+		}
+	case *pairingsFile != "":
+		f, err := os.Open(*pairingsFile)
+		if err != nil {
+			return err
+		}
+		pairings, err = readPairings(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("%v: %v", *pairingsFile, err)
+		}
+	default:
+		if pairings, err = readPairings(os.Stdin); err != nil {
+			return err
+		}
+	}
+
+	// Unrated players are predicted as newcomers.
+	rating := func(player string) *goglicko.Rating {
+		if r, ok := ratings[player]; ok {
+			return r
+		}
+		return sys.NewRating()
+	}
+
+	tw := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "player\topponent\texpected\twin\tdraw\tloss\tchange if win/draw/loss\topponent change")
+	for _, pairing := range pairings {
+		p, err := goglicko.Predict(rating(pairing[0]), rating(pairing[1]), *drawRate)
+		if err != nil {
+			return err
+		}
+
+		win, draw, loss := p.Outcomes[0], p.Outcomes[1], p.Outcomes[2]
+		fmt.Fprintf(tw, "%v\t%v\t%.3f\t%.3f\t%.3f\t%.3f\t%+.1f/%+.1f/%+.1f\t%+.1f/%+.1f/%+.1f\n",
+			pairing[0], pairing[1], p.Expected,
+			win.Probability, draw.Probability, loss.Probability,
+			win.Change, draw.Change, loss.Change,
+			win.OppChange, draw.OppChange, loss.OppChange)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestPredict(t *testing.T) {
+	ratings := writeFile(t, "ratings.csv", "player,rating,deviation,volatility,games\n"+
+		"alice,1700,80,0.06,20\n"+
+		"bob,1500,80,0.06,20\n")
+	pairings := writeFile(t, "pairings.csv", "alice,bob\nbob,newcomer\n")
+
+	var stdout, stderr bytes.Buffer
+	err := predict([]string{"-ratings", ratings, "-pairings", pairings, "-draw-rate", "0.2"}, &stdout, &stderr)
+	if err != nil {
+		t.Fatalf("Error while predicting: %v\n%v", err, stderr.String())
+	}
+
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Output:\n%v", stdout.String())
+	}
+	fields := strings.Fields(lines[1])
+	if fields[0] != "alice" || fields[1] != "bob" || fields[2] <= "0.5" {
+		t.Errorf("alice should be favoured against bob: %v", lines[1])
+	}
+	if !strings.HasPrefix(lines[2], "bob") || !strings.Contains(lines[2], "newcomer") {
+		t.Errorf("Unrated player not predicted: %v", lines[2])
+	}
+
+	stdout.Reset()
+	if err := predict([]string{"-ratings", ratings, "alice", "bob"}, &stdout, &stderr); err != nil {
+		t.Fatalf("Error while predicting from arguments: %v", err)
+	}
+	if n := strings.Count(stdout.String(), "\n"); n != 2 {
+		t.Errorf("Expected a header and one pairing, got:\n%v", stdout.String())
+	}
+
+	if err := predict([]string{"-ratings", ratings, "alice"}, &stdout, &stderr); err == nil {
+		t.Errorf("Expected an error for an odd number of players")
+	}
+}
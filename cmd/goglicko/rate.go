@@ -0,0 +1,179 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/clavoie/goglicko"
+)
+
+// gameFlags are the flags that control how game files are read.
+type gameFlags struct {
+	format   string
+	header   bool
+	layout   string
+	forfeits bool
+}
+
+func addGameFlags(fs *flag.FlagSet) *gameFlags {
+	gf := &gameFlags{}
+	fs.StringVar(&gf.format, "format", "", "`format` of the game files, csv, jsonl or pgn; guessed from the file extension if empty")
+	fs.BoolVar(&gf.header, "header", false, "CSV game files start with a header row")
+	fs.StringVar(&gf.layout, "date-layout", "2006-01-02", "`layout` of CSV dates, see time.Parse")
+	fs.BoolVar(&gf.forfeits, "forfeits", false, "rate forfeited games in PGN game files")
+	return gf
+}
+
+// readGames reads the games of every file, or of stdin if there are none,
+// numbering them in the order read.
+func (gf *gameFlags) readGames(files []string, stdin io.Reader, stderr io.Writer) ([]*goglicko.Game, error) {
+	if len(files) == 0 {
+		return gf.readFile("standard input", stdin, gf.format, stderr)
+	}
+
+	var games []*goglicko.Game
+	for _, name := range files {
+		format := gf.format
+		if format == "" {
+			format = strings.TrimPrefix(strings.ToLower(filepath.Ext(name)), ".")
+		}
+
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		read, err := gf.readFile(name, f, format, stderr)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+		games = append(games, read...)
+	}
+
+	for i, g := range games {
+		g.ID = int64(i + 1)
+	}
+	return games, nil
+}
+
+func (gf *gameFlags) readFile(name string, r io.Reader, format string, stderr io.Writer) ([]*goglicko.Game, error) {
+	switch format {
+	case "csv", "":
+		cr := goglicko.NewCSVGameReader(r)
+		cr.Header = gf.header
+		cr.DateLayout = gf.layout
+		games, err := cr.ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("%v: %v", name, err)
+		}
+		return games, nil
+
+	case "jsonl", "ndjson":
+		games, lineErrs, err := goglicko.NewJSONLReader(r).ReadAllGames()
+		if err != nil {
+			return nil, fmt.Errorf("%v: %v", name, err)
+		}
+		for _, e := range lineErrs {
+			fmt.Fprintf(stderr, "%v: %v\n", name, e)
+		}
+		if len(lineErrs) > 0 {
+			return nil, fmt.Errorf("%v: %v invalid lines", name, len(lineErrs))
+		}
+		return games, nil
+
+	case "pgn":
+		// Undated games and bad headers are common in old archives, so
+		// such games are reported and left out rather than failing the
+		// whole file.
+		games, lineErrs, err := goglicko.ReadPGNGames(r, gf.forfeits)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %v", name, err)
+		}
+		for _, e := range lineErrs {
+			fmt.Fprintf(stderr, "%v: skipped: %v\n", name, e)
+		}
+		return games, nil
+	}
+
+	return nil, fmt.Errorf("%v: unknown format %q", name, format)
+}
+
+// rate rates game files and writes the resulting rating list as CSV.
+func rate(args []string, stdout, stderr io.Writer) (err error) {
+	fs := flag.NewFlagSet("rate", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: goglicko rate [flags] [game files]")
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "Rates the games, grouped into rating periods by date, and prints the")
+		fmt.Fprintln(stderr, "ratings as CSV. Games are read from standard input if no files are given.")
+		fmt.Fprintln(stderr)
+		fs.PrintDefaults()
+	}
+	sf := addSystemFlags(fs)
+	gf := addGameFlags(fs)
+	period := fs.Duration("period", 7*24*time.Hour, "length of a rating period")
+	origin := fs.String("origin", "", "start of the first rating period as YYYY-MM-DD; the earliest game if empty")
+	out := fs.String("o", "", "write the ratings to `file` instead of standard output")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	sys, err := sf.system(fs)
+	if err != nil {
+		return err
+	}
+
+	var start time.Time
+	if *origin != "" {
+		if start, err = time.Parse("2006-01-02", *origin); err != nil {
+			return err
+		}
+	}
+
+	games, err := gf.readGames(fs.Args(), os.Stdin, stderr)
+	if err != nil {
+		return err
+	}
+
+	// Only the final ratings are printed, so the periods are rated over one
+	// map rather than replayed, which would keep every period's ratings.
+	if start.IsZero() {
+		for _, g := range games {
+			if start.IsZero() || g.Played.Before(start) {
+				start = g.Played
+			}
+		}
+	}
+	periods, err := goglicko.GroupPeriods(games, start, *period)
+	if err != nil {
+		return err
+	}
+	ratings := make(map[string]*goglicko.Rating)
+	for _, p := range periods {
+		if err := goglicko.RatePeriod(ratings, p, sys); err != nil {
+			return err
+		}
+	}
+
+	w := stdout
+	if *out != "" {
+		var f *os.File
+		if f, err = os.Create(*out); err != nil {
+			return err
+		}
+		defer func() {
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+		}()
+		w = f
+	}
+
+	return goglicko.NewCSVRatingWriter(w).WriteAll(ratings)
+}
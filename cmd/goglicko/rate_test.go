@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Error while writing %v: %v", path, err)
+	}
+	return path
+}
+
+func TestRate(t *testing.T) {
+	csvGames := writeFile(t, "games.csv", "alice,bob,1-0,2017-03-01\nbob,carol,½-½,2017-03-09\n")
+	jsonGames := writeFile(t, "games.jsonl",
+		`{"player1": "carol", "player2": "alice", "result": 0, "played": "2017-03-10T00:00:00Z"}`+"\n")
+	system := writeFile(t, "system.json", `{"tau": 0.5, "rating": 1200, "provisional_games": 3}`)
+
+	var stdout, stderr bytes.Buffer
+	err := rate([]string{"-system", system, "-deviation", "300", csvGames, jsonGames}, &stdout, &stderr)
+	if err != nil {
+		t.Fatalf("Error while rating: %v\n%v", err, stderr.String())
+	}
+
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if len(lines) != 4 || lines[0] != "player,rating,deviation,volatility,games,periods,provisional" {
+		t.Fatalf("Output:\n%v", stdout.String())
+	}
+	if !strings.HasPrefix(lines[1], "alice,") || !strings.HasSuffix(lines[1], ",2,2,true") {
+		t.Errorf("alice should be first and provisional with 2 games in 2 periods: %v", lines[1])
+	}
+	for _, line := range lines[1:] {
+		dev, err := strconv.ParseFloat(strings.Split(line, ",")[2], 64)
+		if err != nil || dev > 300 {
+			t.Errorf("Deviation above the -deviation flag: %v", line)
+		}
+	}
+}
+
+func TestRateInvalidLines(t *testing.T) {
+	games := writeFile(t, "games.jsonl", `{"player1": "alice", "player2": "alice", "result": 1, "played": "2017-03-10T00:00:00Z"}`+"\n")
+
+	var stdout, stderr bytes.Buffer
+	if err := rate([]string{games}, &stdout, &stderr); err == nil {
+		t.Errorf("Expected an error for an invalid line")
+	}
+	if !strings.Contains(stderr.String(), "Line 1") {
+		t.Errorf("Invalid line not reported: %v", stderr.String())
+	}
+}
+
+func TestRatePGN(t *testing.T) {
+	games := writeFile(t, "club.pgn", `[White "alice"]
+[Black "bob"]
+[Date "2017.03.01"]
+[Result "1-0"]
+
+1. e4 1-0
+
+[White "bob"]
+[Black "carol"]
+[Date "????.??.??"]
+[Result "0-1"]
+
+1. d4 0-1
+`)
+
+	var stdout, stderr bytes.Buffer
+	if err := rate([]string{games}, &stdout, &stderr); err != nil {
+		t.Fatalf("Error while rating: %v\n%v", err, stderr.String())
+	}
+	if !strings.Contains(stderr.String(), "Line 8") {
+		t.Errorf("Undated game not reported: %v", stderr.String())
+	}
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[1], "alice,") || !strings.HasPrefix(lines[2], "bob,") {
+		t.Errorf("Output:\n%v", stdout.String())
+	}
+}
+
+func TestRateOutput(t *testing.T) {
+	games := writeFile(t, "games.csv", "alice,bob,1-0,2017-03-01\n")
+	out := filepath.Join(t.TempDir(), "ratings.csv")
+
+	var stdout, stderr bytes.Buffer
+	if err := rate([]string{"-o", out, games}, &stdout, &stderr); err != nil {
+		t.Fatalf("Error while rating: %v\n%v", err, stderr.String())
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("Error while reading the output: %v", err)
+	}
+	if lines := strings.Split(strings.TrimSpace(string(data)), "\n"); len(lines) != 3 || stdout.Len() != 0 {
+		t.Errorf("Output file:\n%v\nstandard output:\n%v", string(data), stdout.String())
+	}
+}
+
+func TestRateInvalidSystem(t *testing.T) {
+	games := writeFile(t, "games.csv", "alice,bob,1-0,2017-03-01\n")
+	system := writeFile(t, "system.json", `{"volatility": 0}`)
+
+	for _, args := range [][]string{
+		{"-tau", "0"},
+		{"-tau", "-0.5"},
+		{"-deviation", "0"},
+		{"-volatility", "-0.06"},
+		{"-tau", "NaN"},
+		{"-system", system},
+	} {
+		var stdout, stderr bytes.Buffer
+		if err := rate(append(args, games), &stdout, &stderr); err == nil {
+			t.Errorf("Expected an error for %v", args)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/clavoie/goglicko"
+)
+
+// systemFlags are the flags that configure the rating System. Values come
+// from the defaults, then the -system file, then the individual flags.
+type systemFlags struct {
+	file       string
+	tau        float64
+	rating     float64
+	deviation  float64
+	volatility float64
+
+	provisionalDeviation float64
+	provisionalGames     int
+}
+
+// systemConfig is the JSON form of a System, as read by -system.
+type systemConfig struct {
+	Tau        *float64 `json:"tau"`
+	Rating     *float64 `json:"rating"`
+	Deviation  *float64 `json:"deviation"`
+	Volatility *float64 `json:"volatility"`
+
+	ProvisionalDeviation *float64 `json:"provisional_deviation"`
+	ProvisionalGames     *int     `json:"provisional_games"`
+}
+
+func addSystemFlags(fs *flag.FlagSet) *systemFlags {
+	sf := &systemFlags{}
+	fs.StringVar(&sf.file, "system", "", "JSON `file` with the tau, rating, deviation, volatility, provisional_deviation and provisional_games of the system")
+	fs.Float64Var(&sf.tau, "tau", goglicko.DefaultTau, "system constant constraining volatility")
+	fs.Float64Var(&sf.rating, "rating", goglicko.DefaultRat, "starting rating of new players")
+	fs.Float64Var(&sf.deviation, "deviation", goglicko.DefaultDev, "starting rating deviation of new players")
+	fs.Float64Var(&sf.volatility, "volatility", goglicko.DefaultVol, "starting volatility of new players")
+	fs.Float64Var(&sf.provisionalDeviation, "provisional-deviation", 0, "ratings above this deviation are provisional; 0 for no limit")
+	fs.IntVar(&sf.provisionalGames, "provisional-games", 0, "ratings with fewer games are provisional")
+	return sf
+}
+
+// system builds the System, letting flags set on the command line override
+// the -system file. Tau, deviation and volatility must be positive.
+func (sf *systemFlags) system(fs *flag.FlagSet) (*goglicko.System, error) {
+	tau, rating, deviation, volatility := sf.tau, sf.rating, sf.deviation, sf.volatility
+	provDeviation, provGames := sf.provisionalDeviation, sf.provisionalGames
+
+	if sf.file != "" {
+		data, err := os.ReadFile(sf.file)
+		if err != nil {
+			return nil, err
+		}
+		var c systemConfig
+		if err := json.Unmarshal(data, &c); err != nil {
+			return nil, err
+		}
+
+		set := make(map[string]bool)
+		fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+		for name, v := range map[string]struct {
+			dst *float64
+			src *float64
+		}{
+			"tau":        {&tau, c.Tau},
+			"rating":     {&rating, c.Rating},
+			"deviation":  {&deviation, c.Deviation},
+			"volatility": {&volatility, c.Volatility},
+
+			"provisional-deviation": {&provDeviation, c.ProvisionalDeviation},
+		} {
+			if v.src != nil && !set[name] {
+				*v.dst = *v.src
+			}
+		}
+		if c.ProvisionalGames != nil && !set["provisional-games"] {
+			provGames = *c.ProvisionalGames
+		}
+	}
+
+	for _, v := range []struct {
+		name  string
+		value float64
+	}{{"tau", tau}, {"deviation", deviation}, {"volatility", volatility}} {
+		if !(v.value > 0) {
+			return nil, fmt.Errorf("%v must be positive, got %v", v.name, v.value)
+		}
+	}
+
+	sys := goglicko.NewSystem(rating, deviation, volatility, tau)
+	sys.SetProvisional(provDeviation, provGames)
+	return sys, nil
+}
@@ -0,0 +1,259 @@
+package goglicko
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CSVColumns maps the fields of a Game to the columns of a CSV file, counted
+// from 0. Date and Weight are optional: a negative column means the file
+// doesn't have one. Games without a weight count once.
+type CSVColumns struct {
+	Player1 int
+	Player2 int
+	Result  int
+	Date    int
+	Weight  int
+}
+
+// DefaultCSVColumns reads files laid out as player1,player2,result,date,weight.
+var DefaultCSVColumns = CSVColumns{0, 1, 2, 3, 4}
+
+// CSVGameReader reads games from a CSV file, one game per record. Games are
+// numbered from 1 in the order they are read.
+type CSVGameReader struct {
+	Columns    CSVColumns
+	DateLayout string // Layout of the date column, see time.Parse
+	Header     bool   // Whether the first record is a header to skip
+
+	r      *csv.Reader
+	nextID int64
+}
+
+// NewCSVGameReader creates a reader with DefaultCSVColumns, dates formatted as
+// 2006-01-02 and no header.
+func NewCSVGameReader(r io.Reader) *CSVGameReader {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	return &CSVGameReader{DefaultCSVColumns, "2006-01-02", false, cr, 1}
+}
+
+// Read returns the next game, or io.EOF once the file is exhausted.
+func (r *CSVGameReader) Read() (*Game, error) {
+	if r.Header {
+		r.Header = false
+		if _, err := r.r.Read(); err != nil {
+			return nil, err
+		}
+	}
+
+	record, err := r.r.Read()
+	if err != nil {
+		return nil, err
+	}
+	line, _ := r.r.FieldPos(0)
+
+	field := func(col int) (string, error) {
+		if col >= len(record) {
+			return "", fmt.Errorf("Line %v: missing column %v", line, col)
+		}
+		return strings.TrimSpace(record[col]), nil
+	}
+
+	g := &Game{ID: r.nextID, Weight: 1}
+	if g.Player1, err = field(r.Columns.Player1); err != nil {
+		return nil, err
+	}
+	if g.Player2, err = field(r.Columns.Player2); err != nil {
+		return nil, err
+	}
+
+	res, err := field(r.Columns.Result)
+	if err != nil {
+		return nil, err
+	}
+	if g.Result, err = ParseResult(res); err != nil {
+		return nil, fmt.Errorf("Line %v: %v", line, err)
+	}
+
+	if r.Columns.Date >= 0 {
+		date, err := field(r.Columns.Date)
+		if err != nil {
+			return nil, err
+		}
+		if g.Played, err = time.Parse(r.DateLayout, date); err != nil {
+			return nil, fmt.Errorf("Line %v: %v", line, err)
+		}
+	}
+
+	if r.Columns.Weight >= 0 && r.Columns.Weight < len(record) {
+		weight := strings.TrimSpace(record[r.Columns.Weight])
+		if weight != "" {
+			if g.Weight, err = strconv.ParseFloat(weight, 64); err != nil {
+				return nil, fmt.Errorf("Line %v: invalid weight %q", line, weight)
+			}
+		}
+	}
+
+	if err := g.Validate(); err != nil {
+		return nil, fmt.Errorf("Line %v: %v", line, err)
+	}
+
+	r.nextID++
+	return g, nil
+}
+
+// ReadAll reads every remaining game.
+func (r *CSVGameReader) ReadAll() ([]*Game, error) {
+	var games []*Game
+	for {
+		g, err := r.Read()
+		if err == io.EOF {
+			return games, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		games = append(games, g)
+	}
+}
+
+// ParseResult parses a game result as written for the first player. It
+// accepts scores (1, 0, 0.5, ½), score pairs (1-0, 0-1, ½-½, 1/2-1/2,
+// 0.5-0.5) and the letters W, L and D.
+func ParseResult(s string) (Result, error) {
+	switch strings.ToUpper(strings.TrimSpace(s)) {
+	case "1", "1.0", "1-0", "W", "WIN":
+		return Win, nil
+	case "0", "0.0", "0-1", "L", "LOSS":
+		return Loss, nil
+	case "0.5", ".5", "½", "1/2", "½-½", "1/2-1/2", "0.5-0.5", "D", "DRAW", "=":
+		return Draw, nil
+	}
+
+	return 0, fmt.Errorf("Unknown result %q", s)
+}
+
+// CSVRatingWriter writes a rating list as CSV, with a header row naming the
+// columns player,rating,deviation,volatility,games,periods,provisional.
+type CSVRatingWriter struct {
+	w             *csv.Writer
+	headerWritten bool
+}
+
+// NewCSVRatingWriter creates a rating list writer.
+func NewCSVRatingWriter(w io.Writer) *CSVRatingWriter {
+	return &CSVRatingWriter{csv.NewWriter(w), false}
+}
+
+// Write writes the rating of a player.
+func (w *CSVRatingWriter) Write(player string, r *Rating) error {
+	if !w.headerWritten {
+		w.headerWritten = true
+		err := w.w.Write([]string{"player", "rating", "deviation", "volatility", "games", "periods", "provisional"})
+		if err != nil {
+			return err
+		}
+	}
+
+	return w.w.Write([]string{
+		player,
+		strconv.FormatFloat(r.rating, 'f', -1, 64),
+		strconv.FormatFloat(r.deviation, 'f', -1, 64),
+		strconv.FormatFloat(r.volatility, 'f', -1, 64),
+		strconv.Itoa(r.games),
+		strconv.Itoa(r.periods),
+		strconv.FormatBool(r.Provisional()),
+	})
+}
+
+// WriteAll writes every rating, highest first, and flushes the writer.
+func (w *CSVRatingWriter) WriteAll(ratings map[string]*Rating) error {
+	players := make([]string, 0, len(ratings))
+	for player := range ratings {
+		players = append(players, player)
+	}
+	sort.Slice(players, func(i, j int) bool {
+		ri, rj := ratings[players[i]].rating, ratings[players[j]].rating
+		if ri != rj {
+			return ri > rj
+		}
+		return players[i] < players[j]
+	})
+
+	for _, player := range players {
+		if err := w.Write(player, ratings[player]); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+// Flush writes any buffered data to the underlying io.Writer.
+func (w *CSVRatingWriter) Flush() error {
+	w.w.Flush()
+	return w.w.Error()
+}
+
+// ReadRatingsCSV reads a rating list as written by CSVRatingWriter. The header
+// row names the columns, which may come in any order. The games and periods
+// columns are optional, and provisional and any unknown columns are ignored,
+// since provisional status follows from sys. Ratings are created with sys.
+func ReadRatingsCSV(r io.Reader, sys *System) (map[string]*Rating, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	cols := map[string]int{}
+	for i, name := range header {
+		cols[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"player", "rating", "deviation", "volatility"} {
+		if _, ok := cols[name]; !ok {
+			return nil, fmt.Errorf("Missing %v column", name)
+		}
+	}
+
+	ratings := make(map[string]*Rating)
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return ratings, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := cr.FieldPos(0)
+
+		var values [3]float64
+		for i, name := range []string{"rating", "deviation", "volatility"} {
+			if values[i], err = strconv.ParseFloat(strings.TrimSpace(record[cols[name]]), 64); err != nil {
+				return nil, fmt.Errorf("Line %v: invalid %v %q", line, name, record[cols[name]])
+			}
+		}
+		rating := NewRating(values[0], values[1], values[2], sys)
+
+		var counts [2]int
+		for i, name := range []string{"games", "periods"} {
+			col, ok := cols[name]
+			if !ok || col >= len(record) || strings.TrimSpace(record[col]) == "" {
+				continue
+			}
+			if counts[i], err = strconv.Atoi(strings.TrimSpace(record[col])); err != nil {
+				return nil, fmt.Errorf("Line %v: invalid %v %q", line, name, record[col])
+			}
+		}
+		rating.SetCounts(counts[0], counts[1])
+
+		ratings[strings.TrimSpace(record[cols["player"]])] = rating
+	}
+}
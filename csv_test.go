@@ -0,0 +1,116 @@
+package goglicko
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseResult(t *testing.T) {
+	tests := map[string]Result{
+		"1": Win, "1-0": Win, "w": Win, "W": Win,
+		"0": Loss, "0-1": Loss, "L": Loss,
+		"0.5": Draw, "½": Draw, "½-½": Draw, "1/2-1/2": Draw, "D": Draw,
+	}
+	for s, exp := range tests {
+		res, err := ParseResult(s)
+		if err != nil || res != exp {
+			t.Errorf("ParseResult(%q) = %v, %v; expected %v", s, res, err, exp)
+		}
+	}
+
+	if _, err := ParseResult("2-0"); err == nil {
+		t.Errorf("Expected an error for an unknown result")
+	}
+}
+
+func TestCSVGameReader(t *testing.T) {
+	in := "date,white,black,score,weight\n" +
+		"2017-03-01,alice,bob,1-0,\n" +
+		"2017-03-02, carol, alice, ½-½, 2\n"
+
+	r := NewCSVGameReader(strings.NewReader(in))
+	r.Header = true
+	r.Columns = CSVColumns{Player1: 1, Player2: 2, Result: 3, Date: 0, Weight: 4}
+	games, err := r.ReadAll()
+	if err != nil {
+		t.Fatalf("Error while reading: %v", err)
+	}
+
+	exp := []*Game{
+		{ID: 1, Player1: "alice", Player2: "bob", Result: Win,
+			Played: time.Date(2017, time.March, 1, 0, 0, 0, 0, time.UTC), Weight: 1},
+		{ID: 2, Player1: "carol", Player2: "alice", Result: Draw,
+			Played: time.Date(2017, time.March, 2, 0, 0, 0, 0, time.UTC), Weight: 2},
+	}
+	if len(games) != len(exp) {
+		t.Fatalf("games %v != %v", games, exp)
+	}
+	for i := range exp {
+		if *games[i] != *exp[i] {
+			t.Errorf("games[%v] %v != %v", i, games[i], exp[i])
+		}
+	}
+
+	r = NewCSVGameReader(strings.NewReader("alice,bob,1-0,2017-03-01\nalice,bob,?,2017-03-01\n"))
+	if _, err := r.ReadAll(); err == nil || !strings.Contains(err.Error(), "Line 2") {
+		t.Errorf("Expected an error on line 2, got %v", err)
+	}
+}
+
+func TestCSVRatingWriter(t *testing.T) {
+	sys := NewDefaultSystem()
+	sys.SetProvisional(0, 10)
+	ratings := map[string]*Rating{
+		"bob":   NewRating(1450, 80, 0.06, sys),
+		"alice": NewRating(1600.5, 60, 0.059, sys),
+	}
+	ratings["alice"].SetCounts(12, 3)
+
+	var buf bytes.Buffer
+	if err := NewCSVRatingWriter(&buf).WriteAll(ratings); err != nil {
+		t.Fatalf("Error while writing: %v", err)
+	}
+
+	exp := "player,rating,deviation,volatility,games,periods,provisional\n" +
+		"alice,1600.5,60,0.059,12,3,false\n" +
+		"bob,1450,80,0.06,0,0,true\n"
+	if buf.String() != exp {
+		t.Errorf("Wrote\n%v\nexpected\n%v", buf.String(), exp)
+	}
+}
+
+func TestReadRatingsCSV(t *testing.T) {
+	sys := NewDefaultSystem()
+	ratings := map[string]*Rating{
+		"alice": NewRating(1600.5, 60, 0.059, sys),
+		"bob":   NewRating(1450, 80, 0.06, sys),
+	}
+	ratings["bob"].SetCounts(7, 2)
+
+	var buf bytes.Buffer
+	if err := NewCSVRatingWriter(&buf).WriteAll(ratings); err != nil {
+		t.Fatalf("Error while writing: %v", err)
+	}
+	read, err := ReadRatingsCSV(&buf, sys)
+	if err != nil {
+		t.Fatalf("Error while reading: %v", err)
+	}
+	if len(read) != len(ratings) {
+		t.Fatalf("Read %v, expected %v", read, ratings)
+	}
+	for player, r := range ratings {
+		if !r.MostlyEquals(read[player], 1e-9) {
+			t.Errorf("Read %v for %v, expected %v", read[player], player, r)
+		}
+		if read[player].Games() != r.Games() || read[player].Periods() != r.Periods() {
+			t.Errorf("Read %v games in %v periods for %v, expected %v in %v", read[player].Games(),
+				read[player].Periods(), player, r.Games(), r.Periods())
+		}
+	}
+
+	if _, err := ReadRatingsCSV(strings.NewReader("player,rating\nalice,1500\n"), sys); err == nil {
+		t.Errorf("Expected an error for missing columns")
+	}
+}
@@ -0,0 +1,212 @@
+// Package eval measures how well a rating System predicts games.
+//
+// A backtest plays through a chronological game history period by period.
+// Each game is predicted from the ratings before its period, then the period
+// is rated, so no game is ever predicted with knowledge of its own result.
+package eval
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/clavoie/goglicko"
+)
+
+// DefaultDeviationBuckets are the upper bounds of the deviation buckets of a
+// Report. The last bucket holds everything above the last bound.
+var DefaultDeviationBuckets = []float64{50, 100, 150, 200, 250, 300}
+
+// Predictions are clamped this far away from 0 and 1, so a confident miss
+// costs a lot of log-loss rather than all of it.
+const minProbability = 1e-15
+
+// Backtest configures a run through a game history.
+type Backtest struct {
+	System *goglicko.System
+	Origin time.Time     // Start of the first rating period; the earliest game if zero
+	Period time.Duration // Length of a rating period
+
+	// Upper bounds of the deviation buckets, ascending. A game falls in the
+	// bucket of the larger deviation of its two players. Defaults to
+	// DefaultDeviationBuckets.
+	DeviationBuckets []float64
+
+	// Number of equal width bins of the calibration table. Defaults to 10.
+	CalibrationBins int
+}
+
+// Scores are the predictive metrics of a set of games. Draws count as half a
+// win for log-loss and Brier score, and are left out of the accuracy.
+type Scores struct {
+	Games    int
+	LogLoss  float64 // Mean negative log likelihood of the results
+	Brier    float64 // Mean squared error of the expected scores
+	Accuracy float64 // Share of decisive games whose winner was favoured
+
+	decisive float64 // decisive games, for Accuracy
+	correct  float64 // correctly favoured decisive games, halves for even odds
+}
+
+func (s *Scores) add(predicted float64, res goglicko.Result) {
+	p := math.Min(math.Max(predicted, minProbability), 1-minProbability)
+	actual := float64(res)
+
+	s.Games++
+	s.LogLoss += -(actual*math.Log(p) + (1-actual)*math.Log(1-p))
+	s.Brier += (predicted - actual) * (predicted - actual)
+	if res != goglicko.Draw {
+		s.decisive++
+		switch {
+		case predicted == 0.5:
+			s.correct += 0.5
+		case (predicted > 0.5) == (res == goglicko.Win):
+			s.correct++
+		}
+	}
+}
+
+// finish turns the sums into means.
+func (s *Scores) finish() {
+	if s.Games > 0 {
+		s.LogLoss /= float64(s.Games)
+		s.Brier /= float64(s.Games)
+	}
+	if s.decisive > 0 {
+		s.Accuracy = s.correct / s.decisive
+	}
+}
+
+// CalibrationBin compares predictions in [Lower, Upper) against what happened.
+type CalibrationBin struct {
+	Lower     float64
+	Upper     float64
+	Games     int
+	Predicted float64 // Mean expected score
+	Actual    float64 // Mean actual score
+}
+
+// DeviationBucket holds the scores of the games whose larger deviation was at
+// most MaxDeviation, and above the previous bucket's.
+type DeviationBucket struct {
+	MaxDeviation float64 // +Inf for the last bucket
+	Scores
+}
+
+// Report is the outcome of a backtest.
+type Report struct {
+	Scores
+	Calibration []CalibrationBin
+	ByDeviation []DeviationBucket
+}
+
+// Run predicts and rates every game in turn. Games are predicted from the
+// first player's point of view, with the game's Advantage applied.
+func (b *Backtest) Run(games []*goglicko.Game) (*Report, error) {
+	if b.System == nil {
+		return nil, fmt.Errorf("Backtest needs a System")
+	}
+
+	periods, err := goglicko.GroupPeriods(games, firstPeriodStart(b.Origin, games), b.Period)
+	if err != nil {
+		return nil, err
+	}
+	return b.run(periods)
+}
+
+// firstPeriodStart returns origin, or the time of the earliest game if origin
+// is zero.
+func firstPeriodStart(origin time.Time, games []*goglicko.Game) time.Time {
+	if origin.IsZero() {
+		for _, g := range games {
+			if origin.IsZero() || g.Played.Before(origin) {
+				origin = g.Played
+			}
+		}
+	}
+	return origin
+}
+
+// run is Run over games already grouped into periods. The periods aren't
+// changed, so they can be shared between runs.
+func (b *Backtest) run(periods []*goglicko.Period) (*Report, error) {
+	bounds := b.DeviationBuckets
+	if bounds == nil {
+		bounds = DefaultDeviationBuckets
+	}
+	bins := b.CalibrationBins
+	if bins <= 0 {
+		bins = 10
+	}
+
+	report := &Report{Calibration: make([]CalibrationBin, bins)}
+	for i := range report.Calibration {
+		report.Calibration[i].Lower = float64(i) / float64(bins)
+		report.Calibration[i].Upper = float64(i+1) / float64(bins)
+	}
+	for _, bound := range bounds {
+		report.ByDeviation = append(report.ByDeviation, DeviationBucket{MaxDeviation: bound})
+	}
+	report.ByDeviation = append(report.ByDeviation, DeviationBucket{MaxDeviation: math.Inf(1)})
+
+	ratings := make(map[string]*goglicko.Rating)
+	rating := func(player string) *goglicko.Rating {
+		if r, ok := ratings[player]; ok {
+			return r
+		}
+		return b.System.NewRating()
+	}
+
+	for _, p := range periods {
+		for _, g := range p.Games {
+			r1, r2 := rating(g.Player1), rating(g.Player2)
+			predicted := goglicko.ExpectedScore(shifted(r1, g.Advantage), r2)
+
+			report.add(predicted, g.Result)
+
+			bin := int(predicted * float64(bins))
+			if bin == bins {
+				bin--
+			}
+			report.Calibration[bin].Games++
+			report.Calibration[bin].Predicted += predicted
+			report.Calibration[bin].Actual += float64(g.Result)
+
+			_, dev1, _ := r1.GetValues()
+			_, dev2, _ := r2.GetValues()
+			dev := math.Max(dev1, dev2)
+			for i := range report.ByDeviation {
+				if dev <= report.ByDeviation[i].MaxDeviation {
+					report.ByDeviation[i].add(predicted, g.Result)
+					break
+				}
+			}
+		}
+
+		if err := goglicko.RatePeriod(ratings, p, b.System); err != nil {
+			return nil, err
+		}
+	}
+
+	report.finish()
+	for i := range report.Calibration {
+		if c := &report.Calibration[i]; c.Games > 0 {
+			c.Predicted /= float64(c.Games)
+			c.Actual /= float64(c.Games)
+		}
+	}
+	for i := range report.ByDeviation {
+		report.ByDeviation[i].finish()
+	}
+	return report, nil
+}
+
+// shifted returns r moved by points, or r itself if there is nothing to move.
+func shifted(r *goglicko.Rating, points float64) *goglicko.Rating {
+	if points == 0 {
+		return r
+	}
+
+	rating, dev, vol := r.GetValues()
+	return goglicko.NewRating(rating+points, dev, vol, r.GetSystem())
+}
@@ -0,0 +1,66 @@
+package eval
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/clavoie/goglicko"
+)
+
+var origin = time.Date(2017, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+func TestBacktestFirstGame(t *testing.T) {
+	games := []*goglicko.Game{{ID: 1, Player1: "a", Player2: "b", Result: goglicko.Win, Played: origin, Weight: 1}}
+	b := &Backtest{System: goglicko.NewDefaultSystem(), Period: 24 * time.Hour}
+
+	report, err := b.Run(games)
+	if err != nil {
+		t.Fatalf("Error while running: %v", err)
+	}
+
+	// Two newcomers are an even match.
+	if report.Games != 1 || math.Abs(report.LogLoss-math.Ln2) > 1e-9 ||
+		report.Brier != 0.25 || report.Accuracy != 0.5 {
+		t.Errorf("Scores %+v", report.Scores)
+	}
+	if report.Calibration[5].Games != 1 || report.Calibration[5].Actual != 1 {
+		t.Errorf("Calibration %+v", report.Calibration)
+	}
+	last := report.ByDeviation[len(report.ByDeviation)-1]
+	if last.Games != 1 || !math.IsInf(last.MaxDeviation, 1) {
+		t.Errorf("Newcomers not in the last deviation bucket: %+v", report.ByDeviation)
+	}
+}
+
+func TestBacktestLearns(t *testing.T) {
+	// a always beats b, b always beats c.
+	var games []*goglicko.Game
+	for day := 0; day < 30; day++ {
+		played := origin.Add(time.Duration(day) * 24 * time.Hour)
+		games = append(games,
+			&goglicko.Game{Player1: "a", Player2: "b", Result: goglicko.Win, Played: played, Weight: 1},
+			&goglicko.Game{Player1: "c", Player2: "b", Result: goglicko.Loss, Played: played, Weight: 1},
+			&goglicko.Game{Player1: "a", Player2: "c", Result: goglicko.Win, Played: played, Weight: 1})
+	}
+	b := &Backtest{System: goglicko.NewDefaultSystem(), Period: 24 * time.Hour, CalibrationBins: 4}
+
+	report, err := b.Run(games)
+	if err != nil {
+		t.Fatalf("Error while running: %v", err)
+	}
+	if report.Games != len(games) || report.LogLoss >= math.Ln2 || report.Accuracy < 0.9 {
+		t.Errorf("Scores %+v", report.Scores)
+	}
+	if len(report.Calibration) != 4 || report.Calibration[3].Games == 0 {
+		t.Errorf("Calibration %+v", report.Calibration)
+	}
+
+	total := 0
+	for _, bucket := range report.ByDeviation {
+		total += bucket.Games
+	}
+	if total != len(games) {
+		t.Errorf("Deviation buckets hold %v games, expected %v", total, len(games))
+	}
+}
@@ -0,0 +1,184 @@
+package eval
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/clavoie/goglicko"
+)
+
+// Range bounds the values a Tuner searches for one parameter.
+type Range struct {
+	Min float64
+	Max float64
+}
+
+// Default search ranges of a Tuner.
+var (
+	DefaultTauRange        = Range{0.2, 1.2}
+	DefaultDeviationRange  = Range{50, 500}
+	DefaultVolatilityRange = Range{0.01, 0.2}
+	DefaultPeriods         = []time.Duration{24 * time.Hour, 7 * 24 * time.Hour, 30 * 24 * time.Hour}
+)
+
+// Tuner searches for the System parameters and period length with the lowest
+// backtest log-loss on a game history. Parameters are tuned one at a time, each
+// by golden section search over its range, for a few rounds. Every evaluation
+// is a full backtest, so the cost is roughly Rounds * (3 * Steps + len(Periods))
+// backtests. The games are grouped into periods once per period length.
+type Tuner struct {
+	// The starting point of the search. Its base rating is kept, since it
+	// only shifts every rating and doesn't change predictions.
+	Start  *goglicko.System
+	Origin time.Time // Start of the first rating period; the earliest game if zero
+
+	Tau        Range           // Defaults to DefaultTauRange
+	Deviation  Range           // Base deviation; defaults to DefaultDeviationRange
+	Volatility Range           // Base volatility; defaults to DefaultVolatilityRange
+	Periods    []time.Duration // Candidate period lengths; defaults to DefaultPeriods
+
+	Rounds int // Rounds of tuning every parameter; defaults to 2
+	Steps  int // Golden section steps per parameter and round; defaults to 10
+}
+
+// TunePoint is one evaluated set of parameters.
+type TunePoint struct {
+	Tau        float64
+	Deviation  float64
+	Volatility float64
+	Period     time.Duration
+	LogLoss    float64
+}
+
+// TuneResult is the outcome of a search.
+type TuneResult struct {
+	System  *goglicko.System
+	Period  time.Duration
+	LogLoss float64
+	Curve   []TunePoint // Every evaluation, in the order made
+}
+
+// Tune searches for the best parameters for games.
+func (t *Tuner) Tune(games []*goglicko.Game) (*TuneResult, error) {
+	if t.Start == nil {
+		return nil, fmt.Errorf("Tuner needs a Start System")
+	}
+	if len(games) == 0 {
+		return nil, fmt.Errorf("Tuner needs games to tune on")
+	}
+
+	tauRange := orDefault(t.Tau, DefaultTauRange)
+	devRange := orDefault(t.Deviation, DefaultDeviationRange)
+	volRange := orDefault(t.Volatility, DefaultVolatilityRange)
+	periods := t.Periods
+	if len(periods) == 0 {
+		periods = DefaultPeriods
+	}
+	rounds := t.Rounds
+	if rounds <= 0 {
+		rounds = 2
+	}
+	steps := t.Steps
+	if steps <= 0 {
+		steps = 10
+	}
+
+	baseRating, dev, vol, tau := t.Start.GetValues()
+	best := TunePoint{tau, dev, vol, periods[0], math.Inf(1)}
+	result := &TuneResult{}
+
+	origin := firstPeriodStart(t.Origin, games)
+	grouped := make(map[time.Duration][]*goglicko.Period)
+	var runErr error
+	evaluate := func(p TunePoint) float64 {
+		if runErr != nil {
+			return math.Inf(1)
+		}
+		ps, ok := grouped[p.Period]
+		if !ok {
+			var err error
+			if ps, err = goglicko.GroupPeriods(games, origin, p.Period); err != nil {
+				runErr = err
+				return math.Inf(1)
+			}
+			grouped[p.Period] = ps
+		}
+
+		b := &Backtest{System: goglicko.NewSystem(baseRating, p.Deviation, p.Volatility, p.Tau)}
+		report, err := b.run(ps)
+		if err != nil {
+			runErr = err
+			return math.Inf(1)
+		}
+
+		p.LogLoss = report.LogLoss
+		result.Curve = append(result.Curve, p)
+		if p.LogLoss < best.LogLoss {
+			best = p
+		}
+		return p.LogLoss
+	}
+
+	evaluate(best)
+	for round := 0; round < rounds; round++ {
+		for _, period := range periods {
+			if period != best.Period {
+				p := best
+				p.Period = period
+				evaluate(p)
+			}
+		}
+		goldenSection(tauRange, steps, func(x float64) float64 {
+			p := best
+			p.Tau = x
+			return evaluate(p)
+		})
+		goldenSection(devRange, steps, func(x float64) float64 {
+			p := best
+			p.Deviation = x
+			return evaluate(p)
+		})
+		goldenSection(volRange, steps, func(x float64) float64 {
+			p := best
+			p.Volatility = x
+			return evaluate(p)
+		})
+	}
+	if runErr != nil {
+		return nil, runErr
+	}
+
+	result.System = goglicko.NewSystem(baseRating, best.Deviation, best.Volatility, best.Tau)
+	result.Period = best.Period
+	result.LogLoss = best.LogLoss
+	return result, nil
+}
+
+func orDefault(r, def Range) Range {
+	if r == (Range{}) {
+		return def
+	}
+	return r
+}
+
+// goldenSection narrows r towards the minimum of f, assuming f is unimodal
+// over it, evaluating f steps+1 times.
+func goldenSection(r Range, steps int, f func(float64) float64) {
+	invPhi := (math.Sqrt(5) - 1) / 2
+	a, b := r.Min, r.Max
+	c := b - invPhi*(b-a)
+	d := a + invPhi*(b-a)
+	fc, fd := f(c), f(d)
+	for i := 1; i < steps; i++ {
+		if fc < fd {
+			b, d, fd = d, c, fc
+			c = b - invPhi*(b-a)
+			fc = f(c)
+		} else {
+			a, c, fc = c, d, fd
+			d = a + invPhi*(b-a)
+			fd = f(d)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package eval
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/clavoie/goglicko"
+)
+
+// simulatedGames plays random pairings between players of fixed hidden skill.
+func simulatedGames(players, days, perDay int) []*goglicko.Game {
+	rnd := rand.New(rand.NewSource(1))
+	skills := make([]float64, players)
+	for i := range skills {
+		skills[i] = rnd.NormFloat64() * 300
+	}
+
+	var games []*goglicko.Game
+	for day := 0; day < days; day++ {
+		played := origin.Add(time.Duration(day) * 24 * time.Hour)
+		for n := 0; n < perDay; n++ {
+			i, j := rnd.Intn(players), rnd.Intn(players-1)
+			if j >= i {
+				j++
+			}
+			g := &goglicko.Game{
+				ID:      int64(len(games) + 1),
+				Player1: string(rune('a' + i)),
+				Player2: string(rune('a' + j)),
+				Result:  goglicko.Loss,
+				Played:  played,
+				Weight:  1,
+			}
+			if rnd.Float64() < 1/(1+math.Pow(10, (skills[j]-skills[i])/400)) {
+				g.Result = goglicko.Win
+			}
+			games = append(games, g)
+		}
+	}
+	return games
+}
+
+func TestTune(t *testing.T) {
+	games := simulatedGames(20, 60, 10)
+	tuner := &Tuner{
+		Start:   goglicko.NewDefaultSystem(),
+		Periods: []time.Duration{24 * time.Hour, 7 * 24 * time.Hour},
+		Rounds:  1,
+		Steps:   5,
+	}
+
+	result, err := tuner.Tune(games)
+	if err != nil {
+		t.Fatalf("Error while tuning: %v", err)
+	}
+
+	// The starting point, one other period and 6 evaluations per parameter.
+	if len(result.Curve) != 1+1+3*6 {
+		t.Errorf("Curve has %v points, expected %v", len(result.Curve), 1+1+3*6)
+	}
+	start := result.Curve[0]
+	if start.Period != 24*time.Hour || start.Tau != goglicko.DefaultTau {
+		t.Errorf("Curve starts at %+v", start)
+	}
+	for _, p := range result.Curve {
+		if p.LogLoss < result.LogLoss {
+			t.Errorf("Point %+v beats the result's log-loss %v", p, result.LogLoss)
+		}
+	}
+	if result.LogLoss >= start.LogLoss {
+		t.Errorf("Tuning didn't improve on %v: %v", start.LogLoss, result.LogLoss)
+	}
+
+	b := &Backtest{System: result.System, Period: result.Period}
+	report, err := b.Run(games)
+	if err != nil {
+		t.Fatalf("Error while running: %v", err)
+	}
+	if report.LogLoss != result.LogLoss {
+		t.Errorf("Tuned system scores %v, expected %v", report.LogLoss, result.LogLoss)
+	}
+}
+
+func TestTuneErrors(t *testing.T) {
+	if _, err := (&Tuner{}).Tune(simulatedGames(2, 1, 1)); err == nil {
+		t.Errorf("Expected an error without a Start System")
+	}
+	if _, err := (&Tuner{Start: goglicko.NewDefaultSystem()}).Tune(nil); err == nil {
+		t.Errorf("Expected an error without games")
+	}
+}
+
+// BenchmarkTune tunes on a million games, the size of a large site's yearly
+// history. Run it with -benchtime=1x.
+func BenchmarkTune(b *testing.B) {
+	games := simulatedGames(2000, 365, 2740)
+	tuner := &Tuner{
+		Start:   goglicko.NewDefaultSystem(),
+		Periods: []time.Duration{7 * 24 * time.Hour, 30 * 24 * time.Hour},
+		Rounds:  1,
+		Steps:   3,
+	}
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := tuner.Tune(games); err != nil {
+			b.Fatalf("Error while tuning: %v", err)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package goglicko
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Game is the outcome of a single game between two players.
+type Game struct {
+	ID      int64     // Identifies the game within a Store or game log
+	Player1 string    // The player Result is recorded for
+	Player2 string    // Player1's opponent
+	Result  Result    // Outcome of the game from Player1's point of view
+	Played  time.Time // When the game was played
+	Weight  float64   // How much the game counts, relative to 1. Must be positive
+
+	// Rating points Player1 is treated as being stronger by for this game, to
+	// account for a handicap or a first move advantage. May be negative.
+	Advantage float64
+}
+
+// Validate checks that the game can be rated.
+func (g *Game) Validate() error {
+	if g.Player1 == "" || g.Player2 == "" {
+		return fmt.Errorf("Game %v is missing a player", g.ID)
+	}
+	if g.Player1 == g.Player2 {
+		return fmt.Errorf("Game %v has %v playing themselves", g.ID, g.Player1)
+	}
+	if math.IsNaN(float64(g.Result)) || g.Result < Loss || g.Result > Win {
+		return fmt.Errorf("Game %v has result %v outside of [%v, %v]", g.ID, g.Result, Loss, Win)
+	}
+	if !(g.Weight > 0) {
+		return fmt.Errorf("Game %v has weight %v, which must be positive", g.ID, g.Weight)
+	}
+	return nil
+}
+
+// Period is a rating period: the games played between Start (inclusive) and
+// End (exclusive), which are rated together as a single update.
+type Period struct {
+	ID    int64
+	Start time.Time
+	End   time.Time
+	Games []*Game
+}
+
+// Opposite returns the result as seen by the other player.
+func (r Result) Opposite() Result {
+	return 1 - r
+}
@@ -0,0 +1,30 @@
+package goglicko
+
+import (
+	"math"
+	"testing"
+)
+
+func TestGameValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		game  Game
+		valid bool
+	}{
+		{"Valid", Game{Player1: "a", Player2: "b", Result: Draw, Weight: 1}, true},
+		{"MissingPlayer", Game{Player1: "a", Result: Win, Weight: 1}, false},
+		{"PlayingThemselves", Game{Player1: "a", Player2: "a", Result: Win, Weight: 1}, false},
+		{"ResultAboveWin", Game{Player1: "a", Player2: "b", Result: 1.5, Weight: 1}, false},
+		{"ResultBelowLoss", Game{Player1: "a", Player2: "b", Result: -0.5, Weight: 1}, false},
+		{"NaNResult", Game{Player1: "a", Player2: "b", Result: Result(math.NaN()), Weight: 1}, false},
+		{"ZeroWeight", Game{Player1: "a", Player2: "b", Result: Win}, false},
+		{"NaNWeight", Game{Player1: "a", Player2: "b", Result: Win, Weight: math.NaN()}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.game.Validate(); (err == nil) != test.valid {
+				t.Errorf("Validate(%+v) = %v", test.game, err)
+			}
+		})
+	}
+}
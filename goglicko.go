@@ -37,6 +37,7 @@ package goglicko
 import (
 	"fmt"
 	"math"
+	"time"
 )
 
 const (
@@ -95,12 +96,25 @@ func gee(dev float64) float64 {
 	return 1 / math.Sqrt(1+3*dev*dev/piSq)
 }
 
+// The weight of game i. Without weights, a game counts once.
+func weightAt(weights []float64, i int) float64 {
+	if weights == nil {
+		return 1
+	}
+	return weights[i]
+}
+
 // Estimate the variance of the team/player's rating based only on game
 // outcomes. Note, it must be true that len(ees) == len(gees).
 func estVariance(gees, ees []float64) float64 {
+	return weightedEstVariance(gees, ees, nil)
+}
+
+// estVariance, with each game's term scaled by its weight.
+func weightedEstVariance(gees, ees, weights []float64) float64 {
 	out := 0.0
 	for i := range gees {
-		out += sq(gees[i]) * ees[i] * (1 - ees[i])
+		out += weightAt(weights, i) * sq(gees[i]) * ees[i] * (1 - ees[i])
 	}
 	return 1.0 / out
 }
@@ -111,15 +125,26 @@ func estVariance(gees, ees []float64) float64 {
 // Note: This function is like the 'delta' in the algorithm, but here we don't
 // multiply by the estimated variance.
 func estImprovePartial(gees, ees []float64, r []Result) float64 {
+	return weightedEstImprovePartial(gees, ees, r, nil)
+}
+
+// estImprovePartial, with each game's term scaled by its weight.
+func weightedEstImprovePartial(gees, ees []float64, r []Result, weights []float64) float64 {
 	out := 0.0
 	for i := range gees {
-		out += gees[i] * (float64(r[i]) - ees[i])
+		out += weightAt(weights, i) * gees[i] * (float64(r[i]) - ees[i])
 	}
 	return out
 }
 
 // Calculate the new volatility for a Player.
 func (p *Rating) newVolatility(estVar, estImp float64) float64 {
+	return p.solveVolatility(estVar, estImp, nil)
+}
+
+// newVolatility, recording each iteration's estimate of the volatility into
+// trace if it isn't nil.
+func (p *Rating) solveVolatility(estVar, estImp float64, trace *Trace) float64 {
 	epsilon := 0.000001
 	a := math.Log(sq(p.volatility))
 	deltaSq := sq(estImp)
@@ -164,9 +189,13 @@ func (p *Rating) newVolatility(estVar, estImp float64) float64 {
 		B = C
 		fB = fC
 		iter++
+		if trace != nil {
+			trace.VolatilityPath = append(trace.VolatilityPath, math.Exp(A/2))
+		}
 	}
-	if iter == maxIter-1 {
-		fmt.Errorf("Fall through! Too many iterations")
+	if trace != nil {
+		trace.Iterations = iter
+		trace.Converged = math.Abs(B-A) <= epsilon
 	}
 
 	newVol := math.Exp(A / 2)
@@ -185,29 +214,63 @@ func newRatingVal(oldRating, newDev, estImpPart float64) float64 {
 	return oldRating + newDev*newDev*estImpPart
 }
 
-// Update re-calculates the values of Rating from the results of a match
+// Update re-calculates the values of Rating from the results of a match. With
+// no opponents, the player sat out the rating period and only the deviation
+// grows, as in Step 6. If the rating has a History, a Snapshot of the new
+// values is recorded into it as of now; UpdateAt records another time.
 func (player *Rating) Update(opponents []*Rating, res []Result) error {
+	return player.updateAt(time.Now(), opponents, res, nil)
+}
+
+// update is Update with each game weighted, see weightAt. weights may be nil.
+// If trace isn't nil, the intermediate values of each step are recorded in it.
+func (player *Rating) update(opponents []*Rating, res []Result, weights []float64, trace *Trace) error {
 	if len(opponents) != len(res) {
 		return fmt.Errorf("Number of opponents must == number of results. %v != %v",
 			len(opponents), len(res))
 	}
+	if weights != nil && len(weights) != len(res) {
+		return fmt.Errorf("Number of weights must == number of results. %v != %v",
+			len(weights), len(res))
+	}
 
 	p2 := player.toGlicko2()
+	if trace != nil {
+		trace.start(player, p2)
+	}
+
+	if len(opponents) == 0 {
+		player.updateIdle(trace)
+		return nil
+	}
+
 	gees := make([]float64, len(opponents))
 	ees := make([]float64, len(opponents))
 	for i := range opponents {
 		o := opponents[i].toGlicko2()
 		gees[i] = gee(o.deviation)
 		ees[i] = ee(p2.rating, o.rating, o.deviation)
+		if trace != nil {
+			trace.Opponents = append(trace.Opponents, OpponentTrace{
+				o.rating, o.deviation, res[i], weightAt(weights, i), gees[i], ees[i]})
+		}
 	}
 
-	estVar := estVariance(gees, ees)
-	estImpPart := estImprovePartial(gees, ees, res)
+	estVar := weightedEstVariance(gees, ees, weights)
+	estImpPart := weightedEstImprovePartial(gees, ees, res, weights)
 	estImp := estVar * estImpPart
 
-	newVol := p2.newVolatility(estVar, estImp)
+	newVol := p2.solveVolatility(estVar, estImp, trace)
 	newDev := newDeviation(p2.deviation, newVol, estVar)
 	newRating := newRatingVal(p2.rating, newDev, estImpPart)
+	if trace != nil {
+		trace.Variance = estVar
+		trace.Delta = estImp
+		trace.NewVolatility = newVol
+		trace.PrePeriodPhi = math.Sqrt(sq(p2.deviation) + sq(newVol))
+		trace.NewMu = newRating
+		trace.NewPhi = newDev
+	}
 
 	p2.rating = newRating
 	p2.deviation = newDev
@@ -217,10 +280,73 @@ func (player *Rating) Update(opponents []*Rating, res []Result) error {
 	player.rating = p2.rating
 	player.deviation = p2.deviation
 	player.volatility = p2.volatility
+	player.games += len(opponents)
+	player.periods++
 
-	// Upper bound by the Default Deviation.
+	preClamp := player.deviation
+	capped := player.capDeviation()
+	if trace != nil {
+		trace.finish(player, preClamp, capped)
+	}
+	return nil
+}
+
+// updateIdle grows the deviation of a player who didn't compete in a rating
+// period by their volatility. If trace isn't nil, the steps are recorded in it.
+func (player *Rating) updateIdle(trace *Trace) {
+	p2 := player.toGlicko2()
+	phi := math.Sqrt(sq(p2.deviation) + sq(p2.volatility))
+	p2.deviation = phi
+	player.deviation = p2.fromGlicko2().deviation
+
+	preClamp := player.deviation
+	capped := player.capDeviation()
+	if trace != nil {
+		trace.PrePeriodPhi = phi
+		trace.NewMu, trace.NewPhi, trace.NewVolatility = p2.rating, phi, p2.volatility
+		trace.Converged = true
+		trace.finish(player, preClamp, capped)
+	}
+}
+
+// capDeviation upper bounds the deviation by the Default Deviation of the
+// System, returning whether it had to.
+func (player *Rating) capDeviation() bool {
 	if player.deviation > player.system.baseDeviation {
 		player.deviation = player.system.baseDeviation
+		return true
+	}
+	return false
+}
+
+// UpdateWithTrace is Update, also returning the intermediate values of every
+// step, the convergence of the volatility solver, and whether the deviation was
+// capped.
+func (player *Rating) UpdateWithTrace(opponents []*Rating, res []Result) (*Trace, error) {
+	trace := &Trace{}
+	if err := player.update(opponents, res, nil, trace); err != nil {
+		return nil, err
+	}
+	if player.history != nil {
+		player.history.Record(player.Snapshot(time.Now(), len(opponents)))
+	}
+	return trace, nil
+}
+
+// UpdateAt is Update for a rating period ending at time t. If the rating has a
+// History, a Snapshot of the new values is recorded into it.
+func (player *Rating) UpdateAt(t time.Time, opponents []*Rating, res []Result) error {
+	return player.updateAt(t, opponents, res, nil)
+}
+
+// updateAt is UpdateAt with each game weighted, see weightAt.
+func (player *Rating) updateAt(t time.Time, opponents []*Rating, res []Result, weights []float64) error {
+	if err := player.update(opponents, res, weights, nil); err != nil {
+		return err
+	}
+
+	if player.history != nil {
+		player.history.Record(player.Snapshot(t, len(opponents)))
 	}
 
 	return nil
@@ -265,7 +391,8 @@ func resultsExcept(index int, results []Result) []Result {
 	return rs
 }
 
-// Update re-calculates the rating for the results of a match for all players involved
+// Update re-calculates the rating for the results of a match for all players involved.
+// Players with a History get a Snapshot of their new values, as with Rating.Update.
 func Update(players []*Rating, results []Result) error {
 	// players will be updated as the re-calculation goes on. create a snapshot of
 	// the ratings before recalibration to use to calculate the new rating values
@@ -0,0 +1,162 @@
+package goglicko
+
+import (
+	"math"
+	"sort"
+)
+
+// PairRecord is a player's record against one opponent, next to what their
+// ratings at the time of each game predicted.
+type PairRecord struct {
+	Player   string
+	Opponent string
+
+	Games  int
+	Wins   int
+	Draws  int
+	Losses int // Partial results, such as 0.75, count as none of the three
+
+	Weight   float64 // Sum of the games' weights, the points at stake
+	Score    float64 // Points scored by Player
+	Expected float64 // Sum of Player's expected scores
+	Variance float64 // Sum of the variances of Player's scores, for Z
+}
+
+// Surplus returns how many more points the player scored than expected. It
+// is negative if they underperformed.
+func (r PairRecord) Surplus() float64 {
+	return r.Score - r.Expected
+}
+
+// Z returns the surplus in standard deviations, telling a real edge from a
+// lucky streak: beyond about 2 is unlikely to be chance. It is 0 without games.
+func (r PairRecord) Z() float64 {
+	if r.Variance == 0 {
+		return 0
+	}
+	return r.Surplus() / math.Sqrt(r.Variance)
+}
+
+// opposite returns the record from the opponent's point of view.
+func (r PairRecord) opposite() PairRecord {
+	return PairRecord{
+		Player:   r.Opponent,
+		Opponent: r.Player,
+		Games:    r.Games,
+		Wins:     r.Losses,
+		Draws:    r.Draws,
+		Losses:   r.Wins,
+		Weight:   r.Weight,
+		Score:    r.Weight - r.Score,
+		Expected: r.Weight - r.Expected,
+		Variance: r.Variance,
+	}
+}
+
+// HeadToHead tracks the games between every pair of players.
+type HeadToHead struct {
+	pairs     map[[2]string]*PairRecord // keyed and told from the lesser player
+	opponents map[string]map[string]bool
+}
+
+// NewHeadToHead creates an empty tracker.
+func NewHeadToHead() *HeadToHead {
+	return &HeadToHead{make(map[[2]string]*PairRecord), make(map[string]map[string]bool)}
+}
+
+// Add records a game, given both players' ratings from before it. The game's
+// Advantage is applied to the expected score, and its score and expected
+// score are scaled by its Weight, so its variance by Weight squared. Invalid
+// games are rejected.
+func (h *HeadToHead) Add(g *Game, r1, r2 *Rating) error {
+	if err := g.Validate(); err != nil {
+		return err
+	}
+
+	e := ExpectedScore(shifted(r1, g.Advantage), r2)
+	rec := PairRecord{Player: g.Player1, Opponent: g.Player2, Games: 1, Weight: g.Weight,
+		Score: g.Weight * float64(g.Result), Expected: g.Weight * e,
+		Variance: g.Weight * g.Weight * e * (1 - e)}
+	switch g.Result {
+	case Win:
+		rec.Wins = 1
+	case Draw:
+		rec.Draws = 1
+	case Loss:
+		rec.Losses = 1
+	}
+	if g.Player2 < g.Player1 {
+		rec = rec.opposite()
+	}
+
+	key := [2]string{rec.Player, rec.Opponent}
+	pair, ok := h.pairs[key]
+	if !ok {
+		pair = &PairRecord{Player: rec.Player, Opponent: rec.Opponent}
+		h.pairs[key] = pair
+		for _, players := range [][2]string{key, {key[1], key[0]}} {
+			if h.opponents[players[0]] == nil {
+				h.opponents[players[0]] = make(map[string]bool)
+			}
+			h.opponents[players[0]][players[1]] = true
+		}
+	}
+	pair.Games++
+	pair.Wins += rec.Wins
+	pair.Draws += rec.Draws
+	pair.Losses += rec.Losses
+	pair.Weight += rec.Weight
+	pair.Score += rec.Score
+	pair.Expected += rec.Expected
+	pair.Variance += rec.Variance
+	return nil
+}
+
+// AddPeriod records the games of a rating period, given the ratings from
+// before it; call it before RatePeriod. Players without a rating are
+// expected to play at the starting rating of sys. It stops at the first
+// invalid game, leaving the games before it recorded.
+func (h *HeadToHead) AddPeriod(ratings map[string]*Rating, p *Period, sys *System) error {
+	rating := func(player string) *Rating {
+		if r, ok := ratings[player]; ok {
+			return r
+		}
+		return sys.NewRating()
+	}
+
+	for _, g := range p.Games {
+		if err := h.Add(g, rating(g.Player1), rating(g.Player2)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Record returns player's record against opponent.
+func (h *HeadToHead) Record(player, opponent string) PairRecord {
+	if player < opponent {
+		if pair, ok := h.pairs[[2]string{player, opponent}]; ok {
+			return *pair
+		}
+	} else if pair, ok := h.pairs[[2]string{opponent, player}]; ok {
+		return pair.opposite()
+	}
+	return PairRecord{Player: player, Opponent: opponent}
+}
+
+// Opponents returns player's record against each opponent they played, best
+// surplus first.
+func (h *HeadToHead) Opponents(player string) []PairRecord {
+	var records []PairRecord
+	for opponent := range h.opponents[player] {
+		records = append(records, h.Record(player, opponent))
+	}
+	sort.Slice(records, func(i, j int) bool {
+		si, sj := records[i].Surplus(), records[j].Surplus()
+		if si != sj {
+			return si > sj
+		}
+		return records[i].Opponent < records[j].Opponent
+	})
+	return records
+}
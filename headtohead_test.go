@@ -0,0 +1,139 @@
+package goglicko
+
+import (
+	"math"
+	"testing"
+)
+
+func TestHeadToHead(t *testing.T) {
+	sys := NewDefaultSystem()
+	strong := NewRating(1700, 50, DefaultVol, sys)
+	weak := NewRating(1500, 50, DefaultVol, sys)
+	e := ExpectedScore(weak, strong)
+
+	h := NewHeadToHead()
+	for _, g := range []*Game{
+		{Player1: "weak", Player2: "strong", Result: Win, Weight: 1},
+		{Player1: "strong", Player2: "weak", Result: Draw, Weight: 1},
+		{Player1: "strong", Player2: "weak", Result: Win, Weight: 1},
+	} {
+		r1, r2 := weak, strong
+		if g.Player1 == "strong" {
+			r1, r2 = strong, weak
+		}
+		if err := h.Add(g, r1, r2); err != nil {
+			t.Fatalf("Error while adding %+v: %v", g, err)
+		}
+	}
+
+	rec := h.Record("weak", "strong")
+	if rec.Player != "weak" || rec.Opponent != "strong" || rec.Games != 3 ||
+		rec.Wins != 1 || rec.Draws != 1 || rec.Losses != 1 || rec.Score != 1.5 {
+		t.Errorf("Record %+v", rec)
+	}
+	if !floatsMostlyEqual(rec.Expected, 3*e, 1e-9) || !floatsMostlyEqual(rec.Surplus(), 1.5-3*e, 1e-9) {
+		t.Errorf("Expected %v, surplus %v", rec.Expected, rec.Surplus())
+	}
+	if rec.Z() <= 0 {
+		t.Errorf("Z %v should be positive for an overperformance", rec.Z())
+	}
+
+	opp := h.Record("strong", "weak")
+	if opp.Wins != 1 || opp.Losses != 1 || opp.Score != 1.5 ||
+		!floatsMostlyEqual(opp.Surplus(), -rec.Surplus(), 1e-9) || !floatsMostlyEqual(opp.Z(), -rec.Z(), 1e-9) {
+		t.Errorf("Opposite record %+v", opp)
+	}
+
+	if none := h.Record("weak", "nobody"); none.Games != 0 || none.Z() != 0 {
+		t.Errorf("Record without games %+v", none)
+	}
+}
+
+func TestHeadToHeadAdvantage(t *testing.T) {
+	sys := NewDefaultSystem()
+	a, b := NewRating(1500, 50, DefaultVol, sys), NewRating(1500, 50, DefaultVol, sys)
+
+	h := NewHeadToHead()
+	if err := h.Add(&Game{Player1: "a", Player2: "b", Result: Draw, Weight: 1, Advantage: 100}, a, b); err != nil {
+		t.Fatalf("Error while adding: %v", err)
+	}
+	if rec := h.Record("a", "b"); rec.Expected <= 0.5 || rec.Surplus() >= 0 {
+		t.Errorf("Advantage not applied: %+v", rec)
+	}
+}
+
+func TestHeadToHeadPartialAndWeighted(t *testing.T) {
+	sys := NewDefaultSystem()
+	a, b := NewRating(1500, 50, DefaultVol, sys), NewRating(1500, 50, DefaultVol, sys)
+
+	h := NewHeadToHead()
+	for _, g := range []*Game{
+		{Player1: "a", Player2: "b", Result: 0.75, Weight: 1},
+		{Player1: "b", Player2: "a", Result: Win, Weight: 2},
+	} {
+		if err := h.Add(g, a, b); err != nil {
+			t.Fatalf("Error while adding %+v: %v", g, err)
+		}
+	}
+
+	rec := h.Record("a", "b")
+	if rec.Games != 2 || rec.Wins != 0 || rec.Draws != 0 || rec.Losses != 1 || rec.Weight != 3 ||
+		rec.Score != 0.75 || !floatsMostlyEqual(rec.Expected, 1.5, 1e-9) || !floatsMostlyEqual(rec.Variance, 1.25, 1e-9) {
+		t.Errorf("Record %+v", rec)
+	}
+	if opp := h.Record("b", "a"); opp.Wins != 1 || opp.Score != 2.25 || !floatsMostlyEqual(opp.Expected, 1.5, 1e-9) {
+		t.Errorf("Opposite record %+v", opp)
+	}
+}
+
+func TestHeadToHeadInvalid(t *testing.T) {
+	sys := NewDefaultSystem()
+	a, b := NewRating(1500, 50, DefaultVol, sys), NewRating(1500, 50, DefaultVol, sys)
+
+	h := NewHeadToHead()
+	for _, g := range []*Game{
+		{Player1: "a", Player2: "b", Result: Result(math.NaN()), Weight: 1},
+		{Player1: "a", Player2: "b", Result: 1.5, Weight: 1},
+		{Player1: "a", Player2: "b", Result: Win},
+		{Player1: "a", Player2: "a", Result: Win, Weight: 1},
+	} {
+		if err := h.Add(g, a, b); err == nil {
+			t.Errorf("Expected an error adding %+v", g)
+		}
+	}
+	if rec := h.Record("a", "b"); rec.Games != 0 {
+		t.Errorf("Invalid games were recorded: %+v", rec)
+	}
+
+	p := &Period{Games: []*Game{{Player1: "a", Player2: "b", Result: Win}}}
+	if err := h.AddPeriod(map[string]*Rating{}, p, sys); err == nil {
+		t.Errorf("Expected an error adding a period with an invalid game")
+	}
+}
+
+func TestHeadToHeadOpponents(t *testing.T) {
+	sys := NewDefaultSystem()
+	ratings := map[string]*Rating{"a": NewRating(1500, 50, DefaultVol, sys)}
+	p := &Period{Games: []*Game{
+		{Player1: "a", Player2: "b", Result: Win, Weight: 1},
+		{Player1: "c", Player2: "a", Result: Win, Weight: 1},
+		{Player1: "a", Player2: "d", Result: Draw, Weight: 1},
+	}}
+
+	h := NewHeadToHead()
+	if err := h.AddPeriod(ratings, p, sys); err != nil {
+		t.Fatalf("Error while adding the period: %v", err)
+	}
+
+	records := h.Opponents("a")
+	if len(records) != 3 || records[0].Opponent != "b" || records[1].Opponent != "d" ||
+		records[2].Opponent != "c" {
+		t.Fatalf("Opponents %+v", records)
+	}
+	if !floatsMostlyEqual(records[0].Expected, 0.5, 1e-9) {
+		t.Errorf("Unrated b should start at the base rating: %+v", records[0])
+	}
+	if len(h.Opponents("b")) != 1 || h.Opponents("nobody") != nil {
+		t.Errorf("Unexpected opponents of b or nobody")
+	}
+}
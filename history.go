@@ -0,0 +1,76 @@
+package goglicko
+
+import (
+	"sort"
+	"time"
+)
+
+// Snapshot is the value of a player's rating at a point in time.
+type Snapshot struct {
+	Time       time.Time
+	Rating     float64
+	Deviation  float64
+	Volatility float64
+	Games      int // Games played in the rating period that produced the snapshot
+}
+
+// History is a player's rating snapshots, ordered by time.
+type History struct {
+	snapshots []Snapshot
+}
+
+// NewHistory creates an empty History.
+func NewHistory() *History {
+	return &History{}
+}
+
+// Record adds a snapshot to the history. Snapshots are usually recorded in
+// time order, but late ones are inserted in place. A snapshot recorded at the
+// same time as an existing one is placed after it.
+func (h *History) Record(s Snapshot) {
+	i := sort.Search(len(h.snapshots), func(i int) bool {
+		return h.snapshots[i].Time.After(s.Time)
+	})
+
+	h.snapshots = append(h.snapshots, Snapshot{})
+	copy(h.snapshots[i+1:], h.snapshots[i:])
+	h.snapshots[i] = s
+}
+
+// Len returns the number of recorded snapshots.
+func (h *History) Len() int {
+	return len(h.snapshots)
+}
+
+// Snapshots returns every recorded snapshot, oldest first.
+func (h *History) Snapshots() []Snapshot {
+	return append([]Snapshot(nil), h.snapshots...)
+}
+
+// Range returns the snapshots recorded in [from, to), oldest first.
+func (h *History) Range(from, to time.Time) []Snapshot {
+	start := sort.Search(len(h.snapshots), func(i int) bool {
+		return !h.snapshots[i].Time.Before(from)
+	})
+	end := sort.Search(len(h.snapshots), func(i int) bool {
+		return !h.snapshots[i].Time.Before(to)
+	})
+	if end < start {
+		end = start
+	}
+
+	return append([]Snapshot(nil), h.snapshots[start:end]...)
+}
+
+// At returns the rating as of time t: the latest snapshot recorded at or
+// before t. It returns false if there is no such snapshot.
+func (h *History) At(t time.Time) (Snapshot, bool) {
+	i := sort.Search(len(h.snapshots), func(i int) bool {
+		return h.snapshots[i].Time.After(t)
+	})
+	if i == 0 {
+		return Snapshot{}, false
+	}
+
+	return h.snapshots[i-1], true
+}
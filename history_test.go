@@ -0,0 +1,121 @@
+package goglicko
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHistory(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2017, time.March, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	h := NewHistory()
+	h.Record(Snapshot{Time: day(5), Rating: 1550})
+	h.Record(Snapshot{Time: day(1), Rating: 1500})
+	h.Record(Snapshot{Time: day(3), Rating: 1520})
+
+	t.Run("TestOrdered", func(t *testing.T) {
+		exp := []float64{1500, 1520, 1550}
+		snaps := h.Snapshots()
+		if len(snaps) != len(exp) {
+			t.Fatalf("len(snaps) %v != %v", len(snaps), len(exp))
+		}
+		for i := range exp {
+			if snaps[i].Rating != exp[i] {
+				t.Errorf("snaps[%v].Rating %v != %v", i, snaps[i].Rating, exp[i])
+			}
+		}
+	})
+
+	t.Run("TestAt", func(t *testing.T) {
+		if _, ok := h.At(day(0)); ok {
+			t.Errorf("Found a snapshot before the first one was recorded")
+		}
+		tests := map[int]float64{1: 1500, 2: 1500, 3: 1520, 4: 1520, 30: 1550}
+		for d, exp := range tests {
+			s, ok := h.At(day(d))
+			if !ok || s.Rating != exp {
+				t.Errorf("At(day %v) = %v, %v; expected rating %v", d, s, ok, exp)
+			}
+		}
+	})
+
+	t.Run("TestRange", func(t *testing.T) {
+		snaps := h.Range(day(2), day(5))
+		if len(snaps) != 1 || snaps[0].Rating != 1520 {
+			t.Errorf("Range(day 2, day 5) = %v", snaps)
+		}
+		if snaps := h.Range(day(6), day(2)); len(snaps) != 0 {
+			t.Errorf("Inverted range returned %v", snaps)
+		}
+	})
+}
+
+func TestUpdateAtRecordsHistory(t *testing.T) {
+	at := time.Date(2017, time.March, 3, 0, 0, 0, 0, time.UTC)
+	pl := NewRating(1500, 200, DefaultVol, NewDefaultSystem())
+	pl.SetHistory(NewHistory())
+	opps := []*Rating{
+		NewRating(1400, 30, DefaultVol, NewDefaultSystem()),
+		NewRating(1550, 100, DefaultVol, NewDefaultSystem()),
+	}
+
+	if err := pl.UpdateAt(at, opps, []Result{Win, Loss}); err != nil {
+		t.Fatalf("Error while updating: %v", err)
+	}
+
+	s, ok := pl.History().At(at)
+	if !ok {
+		t.Fatalf("No snapshot was recorded")
+	}
+	if s.Rating != pl.rating || s.Deviation != pl.deviation || s.Games != 2 {
+		t.Errorf("Snapshot %v doesn't match rating %v", s, pl)
+	}
+	if pl.Copy().History() != nil {
+		t.Errorf("Copy shares the rating's history")
+	}
+}
+
+func TestUpdateRecordsHistory(t *testing.T) {
+	before := time.Now()
+	pl := NewRating(1500, 200, DefaultVol, NewDefaultSystem())
+	pl.SetHistory(NewHistory())
+	opp := NewRating(1400, 30, DefaultVol, NewDefaultSystem())
+
+	if err := pl.Update([]*Rating{opp}, []Result{Win}); err != nil {
+		t.Fatalf("Error while updating: %v", err)
+	}
+
+	snaps := pl.History().Snapshots()
+	if len(snaps) != 1 {
+		t.Fatalf("Update recorded %v snapshots, expected 1", len(snaps))
+	}
+	if snaps[0].Time.Before(before) || snaps[0].Rating != pl.rating || snaps[0].Games != 1 {
+		t.Errorf("Snapshot %v doesn't match rating %v updated after %v", snaps[0], pl, before)
+	}
+}
+
+func TestPackageUpdateRecordsHistory(t *testing.T) {
+	players := []*Rating{
+		NewRating(1500, 200, DefaultVol, NewDefaultSystem()),
+		NewRating(1400, 30, DefaultVol, NewDefaultSystem()),
+		NewRating(1550, 100, DefaultVol, NewDefaultSystem()),
+	}
+	players[0].SetHistory(NewHistory())
+	players[2].SetHistory(NewHistory())
+
+	if err := Update(players, []Result{Win, Loss, Draw}); err != nil {
+		t.Fatalf("Error while updating: %v", err)
+	}
+
+	for _, i := range []int{0, 2} {
+		snaps := players[i].History().Snapshots()
+		if len(snaps) != 1 || snaps[0].Rating != players[i].rating || snaps[0].Games != 2 {
+			t.Errorf("Player %v recorded %v, expected one snapshot of %v over 2 games", i, snaps, players[i])
+		}
+	}
+	if players[1].History() != nil {
+		t.Errorf("Player without a history was given one")
+	}
+}
@@ -0,0 +1,213 @@
+package goglicko
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// LineError is an error in one line of a line oriented input. Reading can
+// carry on past it with the next line.
+type LineError struct {
+	Line int
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("Line %v: %v", e.Line, e.Err)
+}
+
+// A game as a JSON object, e.g.
+//
+//	{"id": 7, "player1": "alice", "player2": "bob", "result": "1-0",
+//	 "played": "2017-03-01T19:30:00Z", "weight": 1, "advantage": 0}
+//
+// result is a number or any string ParseResult accepts. id, weight and
+// advantage are optional; weight defaults to 1.
+type jsonGame struct {
+	ID        int64           `json:"id,omitempty"`
+	Player1   string          `json:"player1"`
+	Player2   string          `json:"player2"`
+	Result    json.RawMessage `json:"result"`
+	Played    *time.Time      `json:"played"`
+	Weight    *float64        `json:"weight,omitempty"`
+	Advantage float64         `json:"advantage,omitempty"`
+}
+
+// A player's Snapshot as a JSON object.
+type jsonSnapshot struct {
+	Player     string    `json:"player"`
+	Time       time.Time `json:"time"`
+	Rating     float64   `json:"rating"`
+	Deviation  float64   `json:"deviation"`
+	Volatility float64   `json:"volatility"`
+	Games      int       `json:"games"`
+}
+
+// JSONLReader reads games or rating snapshots from JSON Lines input, one
+// object per line. Blank lines are skipped.
+type JSONLReader struct {
+	s    *bufio.Scanner
+	line int
+}
+
+// NewJSONLReader creates a reader over JSON Lines input.
+func NewJSONLReader(r io.Reader) *JSONLReader {
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 64*1024), 1024*1024)
+	return &JSONLReader{s: s}
+}
+
+// next decodes the next non blank line into v, rejecting unknown fields.
+func (r *JSONLReader) next(v interface{}) error {
+	for r.s.Scan() {
+		r.line++
+		line := bytes.TrimSpace(r.s.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		d := json.NewDecoder(bytes.NewReader(line))
+		d.DisallowUnknownFields()
+		if err := d.Decode(v); err != nil {
+			return &LineError{r.line, err}
+		}
+		if d.More() {
+			return &LineError{r.line, fmt.Errorf("More than one object on the line")}
+		}
+		return nil
+	}
+
+	if err := r.s.Err(); err != nil {
+		return err
+	}
+	return io.EOF
+}
+
+// ReadGame returns the next game, or io.EOF at the end of the input. An
+// invalid line is reported as a *LineError, after which reading can continue.
+func (r *JSONLReader) ReadGame() (*Game, error) {
+	var jg jsonGame
+	if err := r.next(&jg); err != nil {
+		return nil, err
+	}
+
+	g := &Game{
+		ID:        jg.ID,
+		Player1:   jg.Player1,
+		Player2:   jg.Player2,
+		Weight:    1,
+		Advantage: jg.Advantage,
+	}
+	if jg.Weight != nil {
+		g.Weight = *jg.Weight
+	}
+	if jg.Played == nil {
+		return nil, &LineError{r.line, fmt.Errorf("Missing played")}
+	}
+	g.Played = *jg.Played
+
+	if len(jg.Result) == 0 {
+		return nil, &LineError{r.line, fmt.Errorf("Missing result")}
+	}
+	var res interface{}
+	if err := json.Unmarshal(jg.Result, &res); err != nil {
+		return nil, &LineError{r.line, err}
+	}
+	switch res := res.(type) {
+	case float64:
+		g.Result = Result(res)
+	case string:
+		parsed, err := ParseResult(res)
+		if err != nil {
+			return nil, &LineError{r.line, err}
+		}
+		g.Result = parsed
+	default:
+		return nil, &LineError{r.line, fmt.Errorf("Result must be a number or a string")}
+	}
+
+	if err := g.Validate(); err != nil {
+		return nil, &LineError{r.line, err}
+	}
+	return g, nil
+}
+
+// ReadAllGames reads every remaining game. Lines that fail validation don't
+// stop the read: they are returned as *LineErrors alongside the valid games.
+func (r *JSONLReader) ReadAllGames() ([]*Game, []*LineError, error) {
+	var games []*Game
+	var lineErrs []*LineError
+	for {
+		g, err := r.ReadGame()
+		if err == io.EOF {
+			return games, lineErrs, nil
+		}
+		if lineErr, ok := err.(*LineError); ok {
+			lineErrs = append(lineErrs, lineErr)
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		games = append(games, g)
+	}
+}
+
+// ReadSnapshot returns the next player and rating snapshot, or io.EOF at the
+// end of the input. An invalid line is reported as a *LineError, after which
+// reading can continue.
+func (r *JSONLReader) ReadSnapshot() (string, Snapshot, error) {
+	var js jsonSnapshot
+	if err := r.next(&js); err != nil {
+		return "", Snapshot{}, err
+	}
+
+	var err error
+	switch {
+	case js.Player == "":
+		err = fmt.Errorf("Missing player")
+	case js.Time.IsZero():
+		err = fmt.Errorf("Missing time")
+	case !(js.Deviation > 0):
+		err = fmt.Errorf("Deviation %v must be positive", js.Deviation)
+	case !(js.Volatility > 0):
+		err = fmt.Errorf("Volatility %v must be positive", js.Volatility)
+	case js.Games < 0:
+		err = fmt.Errorf("Negative games %v", js.Games)
+	}
+	if err != nil {
+		return "", Snapshot{}, &LineError{r.line, err}
+	}
+
+	return js.Player, Snapshot{js.Time, js.Rating, js.Deviation, js.Volatility, js.Games}, nil
+}
+
+// JSONLWriter writes games or rating snapshots as JSON Lines.
+type JSONLWriter struct {
+	enc *json.Encoder
+}
+
+// NewJSONLWriter creates a JSON Lines writer.
+func NewJSONLWriter(w io.Writer) *JSONLWriter {
+	return &JSONLWriter{json.NewEncoder(w)}
+}
+
+// WriteGame writes a game on its own line, with the result as a number.
+func (w *JSONLWriter) WriteGame(g *Game) error {
+	res, err := json.Marshal(float64(g.Result))
+	if err != nil {
+		return err
+	}
+
+	played, weight := g.Played, g.Weight
+	return w.enc.Encode(jsonGame{g.ID, g.Player1, g.Player2, res, &played, &weight, g.Advantage})
+}
+
+// WriteSnapshot writes a player's rating snapshot on its own line.
+func (w *JSONLWriter) WriteSnapshot(player string, s Snapshot) error {
+	return w.enc.Encode(jsonSnapshot{player, s.Time, s.Rating, s.Deviation, s.Volatility, s.Games})
+}
@@ -0,0 +1,116 @@
+package goglicko
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJSONLReadGames(t *testing.T) {
+	in := `{"id": 1, "player1": "alice", "player2": "bob", "result": "1-0", "played": "2017-03-01T19:30:00Z"}
+
+{"player1": "bob", "player2": "carol", "result": 0.5, "played": "2017-03-02T19:30:00Z", "weight": 2}
+{"player1": "bob", "player2": "bob", "result": 1, "played": "2017-03-02T19:30:00Z"}
+{"player1": "bob", "player2": "carol", "result": 1}
+{"player1": "bob", "player2": "carol", "result": 1, "played": "2017-03-02T19:30:00Z", "colour": "w"}
+not json
+`
+
+	games, lineErrs, err := NewJSONLReader(strings.NewReader(in)).ReadAllGames()
+	if err != nil {
+		t.Fatalf("Error while reading: %v", err)
+	}
+
+	if len(games) != 2 {
+		t.Fatalf("games %v, expected 2", games)
+	}
+	if g := games[0]; g.ID != 1 || g.Player1 != "alice" || g.Result != Win || g.Weight != 1 ||
+		!g.Played.Equal(time.Date(2017, time.March, 1, 19, 30, 0, 0, time.UTC)) {
+		t.Errorf("First game read as %+v", g)
+	}
+	if g := games[1]; g.Result != Draw || g.Weight != 2 {
+		t.Errorf("Second game read as %+v", g)
+	}
+
+	expLines := []int{4, 5, 6, 7}
+	if len(lineErrs) != len(expLines) {
+		t.Fatalf("lineErrs %v, expected lines %v", lineErrs, expLines)
+	}
+	for i, e := range lineErrs {
+		if e.Line != expLines[i] {
+			t.Errorf("lineErrs[%v] on line %v, expected %v: %v", i, e.Line, expLines[i], e)
+		}
+	}
+}
+
+func TestJSONLRoundTrip(t *testing.T) {
+	g := &Game{ID: 3, Player1: "alice", Player2: "bob", Result: Draw,
+		Played: time.Date(2017, time.March, 1, 0, 0, 0, 0, time.UTC), Weight: 1, Advantage: 35}
+	s := Snapshot{time.Date(2017, time.March, 8, 0, 0, 0, 0, time.UTC), 1510.5, 180, 0.06, 4}
+
+	var games, snaps bytes.Buffer
+	if err := NewJSONLWriter(&games).WriteGame(g); err != nil {
+		t.Fatalf("Error while writing game: %v", err)
+	}
+	if err := NewJSONLWriter(&snaps).WriteSnapshot("alice", s); err != nil {
+		t.Fatalf("Error while writing snapshot: %v", err)
+	}
+
+	r := NewJSONLReader(&games)
+	g2, err := r.ReadGame()
+	if err != nil || *g2 != *g {
+		t.Errorf("Read game %+v, %v; expected %+v", g2, err, g)
+	}
+	if _, err := r.ReadGame(); err != io.EOF {
+		t.Errorf("Expected io.EOF, got %v", err)
+	}
+
+	player, s2, err := NewJSONLReader(&snaps).ReadSnapshot()
+	if err != nil || player != "alice" || !s2.Time.Equal(s.Time) || s2.Rating != s.Rating || s2.Games != s.Games {
+		t.Errorf("Read snapshot %v %+v, %v; expected %+v", player, s2, err, s)
+	}
+}
+
+func TestJSONLReadSnapshotInvalid(t *testing.T) {
+	in := `{"player": "alice", "time": "2017-03-08T00:00:00Z", "rating": 1500, "deviation": 200, "volatility": 0.06, "games": 2}
+{"time": "2017-03-08T00:00:00Z", "rating": 1500, "deviation": 200, "volatility": 0.06, "games": 2}
+{"player": "alice", "rating": 1500, "deviation": 200, "volatility": 0.06, "games": 2}
+{"player": "alice", "time": "2017-03-08T00:00:00Z", "rating": 1500, "deviation": 0, "volatility": 0.06, "games": 2}
+{"player": "alice", "time": "2017-03-08T00:00:00Z", "rating": 1500, "deviation": 200, "volatility": -0.06, "games": 2}
+{"player": "alice", "time": "2017-03-08T00:00:00Z", "rating": 1500, "deviation": 200, "volatility": 0.06, "games": -1}
+{"player": "bob", "time": "2017-03-08T00:00:00Z", "rating": 1400, "deviation": 100, "volatility": 0.06, "games": 0}
+`
+
+	r := NewJSONLReader(strings.NewReader(in))
+	var players []string
+	var lines []int
+	for {
+		player, _, err := r.ReadSnapshot()
+		if err == io.EOF {
+			break
+		}
+		if lineErr, ok := err.(*LineError); ok {
+			lines = append(lines, lineErr.Line)
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Error while reading: %v", err)
+		}
+		players = append(players, player)
+	}
+
+	if len(players) != 2 || players[0] != "alice" || players[1] != "bob" {
+		t.Errorf("players %v, expected [alice bob]", players)
+	}
+	expLines := []int{2, 3, 4, 5, 6}
+	if len(lines) != len(expLines) {
+		t.Fatalf("Invalid lines %v, expected %v", lines, expLines)
+	}
+	for i := range expLines {
+		if lines[i] != expLines[i] {
+			t.Errorf("Invalid lines %v, expected %v", lines, expLines)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+// Package leaderboard ranks rated players.
+//
+// A Leaderboard keeps its eligible players sorted as ratings are set and
+// removed, so reading a page or a player's rank doesn't sort anything.
+package leaderboard
+
+import (
+	"sort"
+
+	"github.com/clavoie/goglicko"
+)
+
+// Policy decides the score players are ranked by.
+type Policy int
+
+const (
+	// ByRating ranks players by their rating.
+	ByRating Policy = iota
+	// ByConservative ranks players by their rating less a multiple of their
+	// deviation, so players only rank high once the system is confident.
+	ByConservative
+)
+
+// Options configure a Leaderboard.
+type Options struct {
+	Policy Policy
+
+	// Deviations subtracted from the rating by ByConservative. Defaults to 2.
+	Deviations float64
+
+	// Players with fewer games, or a larger deviation, are tracked but not
+	// ranked. A zero MaxDeviation means no limit.
+	MinGames     int
+	MaxDeviation float64
+
+	// Whether provisional ratings are left unranked, see
+	// goglicko.System.SetProvisional.
+	ExcludeProvisional bool
+}
+
+// Entry is a ranked player. Players with equal scores share a rank, and the
+// next rank skips as many places: 1, 2, 2, 4.
+type Entry struct {
+	Rank        int
+	Player      string
+	Rating      *goglicko.Rating
+	Games       int
+	Provisional bool
+	Score       float64 // What the player is ranked by
+}
+
+// Leaderboard ranks players by the policy of its Options.
+type Leaderboard struct {
+	opts    Options
+	players map[string]*Entry
+	ranked  []*Entry // Eligible players, best first, ties by name
+}
+
+// New creates an empty leaderboard.
+func New(opts Options) *Leaderboard {
+	if opts.Deviations == 0 {
+		opts.Deviations = 2
+	}
+	return &Leaderboard{opts: opts, players: make(map[string]*Entry)}
+}
+
+// Set adds a player or replaces their rating. The rating is copied: Set must
+// be called again whenever it changes. Its game count comes from the rating,
+// and whether it is provisional from its System, see
+// goglicko.Rating.Provisional.
+func (l *Leaderboard) Set(player string, r *goglicko.Rating) {
+	l.Remove(player)
+
+	rating, dev, _ := r.GetValues()
+	e := &Entry{
+		Player:      player,
+		Rating:      r.Copy(),
+		Games:       r.Games(),
+		Provisional: r.Provisional(),
+		Score:       rating,
+	}
+	if l.opts.Policy == ByConservative {
+		e.Score -= l.opts.Deviations * dev
+	}
+	l.players[player] = e
+
+	if e.Games < l.opts.MinGames || (l.opts.MaxDeviation > 0 && dev > l.opts.MaxDeviation) ||
+		(l.opts.ExcludeProvisional && e.Provisional) {
+		return
+	}
+	i := l.search(e)
+	l.ranked = append(l.ranked, nil)
+	copy(l.ranked[i+1:], l.ranked[i:])
+	l.ranked[i] = e
+}
+
+// SetAll sets every rating.
+func (l *Leaderboard) SetAll(ratings map[string]*goglicko.Rating) {
+	for player, r := range ratings {
+		l.Set(player, r)
+	}
+}
+
+// Remove drops a player from the leaderboard.
+func (l *Leaderboard) Remove(player string) {
+	e, ok := l.players[player]
+	if !ok {
+		return
+	}
+	delete(l.players, player)
+
+	if i := l.search(e); i < len(l.ranked) && l.ranked[i] == e {
+		l.ranked = append(l.ranked[:i], l.ranked[i+1:]...)
+	}
+}
+
+// Len returns the number of ranked players.
+func (l *Leaderboard) Len() int {
+	return len(l.ranked)
+}
+
+// Page returns up to limit ranked players, skipping the first offset.
+func (l *Leaderboard) Page(offset, limit int) []Entry {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(l.ranked) || limit <= 0 {
+		return nil
+	}
+	end := offset + limit
+	if end > len(l.ranked) {
+		end = len(l.ranked)
+	}
+
+	page := make([]Entry, 0, end-offset)
+	for i := offset; i < end; i++ {
+		page = append(page, l.entry(i))
+	}
+	return page
+}
+
+// Player returns a player's entry. Its Rank is 0 if they aren't eligible;
+// ok is false if they aren't on the leaderboard at all.
+func (l *Leaderboard) Player(player string) (e Entry, ok bool) {
+	p, ok := l.players[player]
+	if !ok {
+		return Entry{}, false
+	}
+	if i := l.search(p); i < len(l.ranked) && l.ranked[i] == p {
+		return l.entry(i), true
+	}
+	return *p, true
+}
+
+// entry returns the ranked entry at index i, with its rank.
+func (l *Leaderboard) entry(i int) Entry {
+	e := *l.ranked[i]
+	e.Rank = sort.Search(i, func(j int) bool {
+		return l.ranked[j].Score <= e.Score
+	}) + 1
+	return e
+}
+
+// search returns the index at which e is, or would be, ranked.
+func (l *Leaderboard) search(e *Entry) int {
+	return sort.Search(len(l.ranked), func(i int) bool {
+		r := l.ranked[i]
+		if r.Score != e.Score {
+			return r.Score < e.Score
+		}
+		return r.Player >= e.Player
+	})
+}
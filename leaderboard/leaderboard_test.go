@@ -0,0 +1,147 @@
+package leaderboard
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/clavoie/goglicko"
+)
+
+var sys = goglicko.NewDefaultSystem()
+
+func rating(r, rd float64, games int) *goglicko.Rating {
+	rating := goglicko.NewRating(r, rd, goglicko.DefaultVol, sys)
+	rating.SetCounts(games, games)
+	return rating
+}
+
+func players(entries []Entry) string {
+	s := ""
+	for _, e := range entries {
+		s += fmt.Sprintf("%v:%v ", e.Rank, e.Player)
+	}
+	return s
+}
+
+func TestRanking(t *testing.T) {
+	ratings := map[string]*goglicko.Rating{
+		"a": rating(1600, 50, 20),
+		"b": rating(1700, 300, 2),
+		"c": rating(1600, 100, 20),
+		"d": rating(1500, 50, 20),
+	}
+
+	tests := []struct {
+		opts     Options
+		expected string
+	}{
+		{Options{}, "1:b 2:a 2:c 4:d "},
+		{Options{Policy: ByConservative}, "1:a 2:c 2:d 4:b "},
+		{Options{Policy: ByConservative, Deviations: 1}, "1:a 2:c 3:d 4:b "},
+		{Options{MinGames: 10}, "1:a 1:c 3:d "},
+		{Options{MaxDeviation: 75}, "1:a 2:d "},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%+v", test.opts), func(t *testing.T) {
+			l := New(test.opts)
+			l.SetAll(ratings)
+			if got := players(l.Page(0, 10)); got != test.expected {
+				t.Errorf("Ranked %q, expected %q", got, test.expected)
+			}
+		})
+	}
+}
+
+func TestUpdates(t *testing.T) {
+	l := New(Options{MinGames: 1})
+	l.Set("a", rating(1500, 50, 1))
+	l.Set("b", rating(1600, 50, 1))
+	l.Set("c", rating(1700, 50, 0))
+	if got := players(l.Page(0, 10)); got != "1:b 2:a " {
+		t.Errorf("Ranked %q", got)
+	}
+
+	l.Set("c", rating(1700, 50, 1))
+	l.Set("a", rating(1800, 50, 2))
+	if got := players(l.Page(0, 10)); got != "1:a 2:c 3:b " {
+		t.Errorf("Ranked %q after updates", got)
+	}
+
+	l.Remove("c")
+	l.Remove("missing")
+	if got := players(l.Page(0, 10)); got != "1:a 2:b " || l.Len() != 2 {
+		t.Errorf("Ranked %q after a removal", got)
+	}
+}
+
+func TestRatingCopied(t *testing.T) {
+	r := rating(1500, 50, 1)
+	l := New(Options{})
+	l.Set("a", r)
+	if err := r.Update([]*goglicko.Rating{rating(1500, 50, 1)}, []goglicko.Result{goglicko.Win}); err != nil {
+		t.Fatalf("Error while updating: %v", err)
+	}
+
+	e, _ := l.Player("a")
+	if got, _, _ := e.Rating.GetValues(); got != 1500 || e.Score != 1500 || e.Games != 1 {
+		t.Errorf("Leaderboard rating changed to %v", got)
+	}
+}
+
+func TestPagination(t *testing.T) {
+	l := New(Options{})
+	for i := 0; i < 10; i++ {
+		l.Set(fmt.Sprintf("p%v", i), rating(1500+float64(i)*10, 50, 1))
+	}
+
+	tests := []struct {
+		offset, limit int
+		expected      string
+	}{
+		{0, 3, "1:p9 2:p8 3:p7 "},
+		{8, 5, "9:p1 10:p0 "},
+		{-1, 1, "1:p9 "},
+		{10, 5, ""},
+		{0, 0, ""},
+	}
+	for _, test := range tests {
+		if got := players(l.Page(test.offset, test.limit)); got != test.expected {
+			t.Errorf("Page(%v, %v) was %q, expected %q", test.offset, test.limit, got, test.expected)
+		}
+	}
+}
+
+func TestPlayer(t *testing.T) {
+	l := New(Options{MinGames: 5})
+	l.Set("a", rating(1500, 50, 10))
+	l.Set("b", rating(1500, 50, 10))
+	l.Set("c", rating(1900, 50, 1))
+
+	if e, ok := l.Player("b"); !ok || e.Rank != 1 || e.Games != 10 {
+		t.Errorf("Player b was %+v, %v", e, ok)
+	}
+	if e, ok := l.Player("c"); !ok || e.Rank != 0 {
+		t.Errorf("Ineligible player c was %+v, %v", e, ok)
+	}
+	if _, ok := l.Player("d"); ok {
+		t.Errorf("Unknown player d found")
+	}
+}
+
+func TestProvisional(t *testing.T) {
+	provisional := goglicko.NewDefaultSystem()
+	provisional.SetProvisional(100, 5)
+
+	l := New(Options{ExcludeProvisional: true})
+	l.Set("new", goglicko.NewRating(1900, 50, goglicko.DefaultVol, provisional))
+	established := goglicko.NewRating(1500, 50, goglicko.DefaultVol, provisional)
+	established.SetCounts(5, 2)
+	l.Set("established", established)
+
+	if got := players(l.Page(0, 10)); got != "1:established " {
+		t.Errorf("Ranked %q", got)
+	}
+	if e, ok := l.Player("new"); !ok || e.Rank != 0 || !e.Provisional {
+		t.Errorf("Provisional player was %+v, %v", e, ok)
+	}
+}
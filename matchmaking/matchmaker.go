@@ -0,0 +1,145 @@
+// Package matchmaking pairs waiting players into games by their ratings.
+package matchmaking
+
+import (
+	"sort"
+	"time"
+
+	"github.com/clavoie/goglicko"
+)
+
+// Player is a player waiting for a game.
+type Player struct {
+	ID     string
+	Rating *goglicko.Rating
+	Since  time.Time // When they started waiting
+}
+
+// Match is a game the matchmaker paired.
+type Match struct {
+	Player1 Player
+	Player2 Player
+	Quality float64 // See goglicko.MatchQuality
+}
+
+// Options configure a Matchmaker.
+type Options struct {
+	// Pairs below this quality aren't made, unless one of the players has
+	// waited longer than MaxWait.
+	MinQuality float64
+
+	// Players waiting longer than this are paired first, with their best
+	// available opponent whatever the quality. Zero means no limit.
+	MaxWait time.Duration
+
+	// Number of most recent opponents of each player they aren't paired
+	// with again. Zero allows immediate rematches.
+	AvoidRematches int
+}
+
+// Matchmaker pairs pools of waiting players, remembering who played whom.
+type Matchmaker struct {
+	opts   Options
+	recent map[string][]string // most recent opponents of each player, oldest first
+}
+
+// NewMatchmaker creates a matchmaker.
+func NewMatchmaker(opts Options) *Matchmaker {
+	return &Matchmaker{opts, make(map[string][]string)}
+}
+
+// Played records a game between two players, for avoiding rematches. Pair
+// records the matches it makes itself.
+func (m *Matchmaker) Played(a, b string) {
+	if m.opts.AvoidRematches <= 0 {
+		return
+	}
+	for _, p := range [][2]string{{a, b}, {b, a}} {
+		recent := append(m.recent[p[0]], p[1])
+		if len(recent) > m.opts.AvoidRematches {
+			recent = recent[len(recent)-m.opts.AvoidRematches:]
+		}
+		m.recent[p[0]] = recent
+	}
+}
+
+// rematch returns whether a played b too recently to play again.
+func (m *Matchmaker) rematch(a, b string) bool {
+	for _, opp := range m.recent[a] {
+		if opp == b {
+			return true
+		}
+	}
+	return false
+}
+
+// Pair pairs the players of pool as of now, returning the matches and the
+// players left waiting. Players past MaxWait are paired first, longest
+// waiting first; then the remaining pairs are made greedily, best quality
+// first.
+func (m *Matchmaker) Pair(pool []Player, now time.Time) ([]Match, []Player) {
+	type pair struct {
+		i, j    int
+		quality float64
+	}
+	var pairs []pair
+	for i := range pool {
+		for j := i + 1; j < len(pool); j++ {
+			if pool[i].ID == pool[j].ID || m.rematch(pool[i].ID, pool[j].ID) {
+				continue
+			}
+			pairs = append(pairs, pair{i, j, goglicko.MatchQuality(pool[i].Rating, pool[j].Rating)})
+		}
+	}
+	sort.SliceStable(pairs, func(a, b int) bool {
+		return pairs[a].quality > pairs[b].quality
+	})
+
+	paired := make([]bool, len(pool))
+	var matches []Match
+	match := func(p pair) {
+		paired[p.i], paired[p.j] = true, true
+		matches = append(matches, Match{pool[p.i], pool[p.j], p.quality})
+		m.Played(pool[p.i].ID, pool[p.j].ID)
+	}
+
+	if m.opts.MaxWait > 0 {
+		var overdue []int
+		for i, p := range pool {
+			if now.Sub(p.Since) > m.opts.MaxWait {
+				overdue = append(overdue, i)
+			}
+		}
+		sort.SliceStable(overdue, func(a, b int) bool {
+			return pool[overdue[a]].Since.Before(pool[overdue[b]].Since)
+		})
+		for _, i := range overdue {
+			if paired[i] {
+				continue
+			}
+			for _, p := range pairs {
+				if (p.i == i || p.j == i) && !paired[p.i] && !paired[p.j] {
+					match(p)
+					break
+				}
+			}
+		}
+	}
+
+	for _, p := range pairs {
+		if p.quality < m.opts.MinQuality {
+			break
+		}
+		if !paired[p.i] && !paired[p.j] {
+			match(p)
+		}
+	}
+
+	var waiting []Player
+	for i, p := range pool {
+		if !paired[i] {
+			waiting = append(waiting, p)
+		}
+	}
+	return matches, waiting
+}
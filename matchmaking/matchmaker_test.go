@@ -0,0 +1,91 @@
+package matchmaking
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/clavoie/goglicko"
+)
+
+var (
+	sys = goglicko.NewDefaultSystem()
+	now = time.Date(2017, time.March, 1, 12, 0, 0, 0, time.UTC)
+)
+
+func player(id string, rating float64, waited time.Duration) Player {
+	return Player{id, goglicko.NewRating(rating, 50, goglicko.DefaultVol, sys), now.Add(-waited)}
+}
+
+func pairs(matches []Match) string {
+	s := ""
+	for _, m := range matches {
+		s += fmt.Sprintf("%v-%v ", m.Player1.ID, m.Player2.ID)
+	}
+	return s
+}
+
+func TestPairByQuality(t *testing.T) {
+	pool := []Player{
+		player("a", 1500, 0),
+		player("b", 1900, 0),
+		player("c", 1510, 0),
+		player("d", 1880, 0),
+		player("e", 1200, 0),
+	}
+
+	m := NewMatchmaker(Options{MinQuality: 0.5})
+	matches, waiting := m.Pair(pool, now)
+	if got := pairs(matches); got != "a-c b-d " {
+		t.Errorf("Paired %q", got)
+	}
+	if len(waiting) != 1 || waiting[0].ID != "e" {
+		t.Errorf("Left waiting %+v", waiting)
+	}
+	for _, match := range matches {
+		if match.Quality < 0.5 {
+			t.Errorf("Match %+v below the minimum quality", match)
+		}
+	}
+}
+
+func TestPairMaxWait(t *testing.T) {
+	pool := []Player{
+		player("a", 1500, 0),
+		player("b", 1510, 0),
+		player("e", 1200, 10*time.Minute),
+	}
+
+	m := NewMatchmaker(Options{MinQuality: 0.5, MaxWait: 5 * time.Minute})
+	matches, waiting := m.Pair(pool, now)
+	if got := pairs(matches); got != "a-e " || len(waiting) != 1 || waiting[0].ID != "b" {
+		t.Errorf("Paired %q, left %+v", got, waiting)
+	}
+}
+
+func TestPairAvoidRematches(t *testing.T) {
+	pool := []Player{
+		player("a", 1500, 0),
+		player("b", 1500, 0),
+		player("c", 1600, 0),
+		player("d", 1600, 0),
+	}
+
+	m := NewMatchmaker(Options{AvoidRematches: 1})
+	if got, _ := m.Pair(pool, now); pairs(got) != "a-b c-d " {
+		t.Errorf("First round paired %q", pairs(got))
+	}
+	if got, _ := m.Pair(pool, now); pairs(got) != "a-c b-d " {
+		t.Errorf("Second round paired %q", pairs(got))
+	}
+
+	// Only the last opponent is remembered.
+	if got, _ := m.Pair(pool, now); pairs(got) != "a-b c-d " {
+		t.Errorf("Third round paired %q", pairs(got))
+	}
+
+	m.Played("a", "b")
+	if got, waiting := m.Pair(pool[:2], now); len(got) != 0 || len(waiting) != 2 {
+		t.Errorf("Rematch paired %q", pairs(got))
+	}
+}
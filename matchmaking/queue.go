@@ -0,0 +1,292 @@
+package matchmaking
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/clavoie/goglicko"
+)
+
+// Clock tells the time to a Queue, so tests can control it.
+type Clock interface {
+	Now() time.Time
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker delivers a tick every period of a Clock, as time.Ticker does,
+// dropping ticks the receiver isn't ready for.
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                   { return time.Now() }
+func (systemClock) NewTicker(d time.Duration) Ticker { return systemTicker{time.NewTicker(d)} }
+
+type systemTicker struct{ t *time.Ticker }
+
+func (t systemTicker) C() <-chan time.Time { return t.t.C }
+func (t systemTicker) Stop()               { t.t.Stop() }
+
+// SystemClock is the real time.
+var SystemClock Clock = systemClock{}
+
+// Window is the range of ratings a waiting player accepts as opponents. It
+// starts at Initial points either side of their rating and widens by Growth
+// points per second of waiting, up to Max.
+type Window struct {
+	Initial float64
+	Growth  float64
+	Max     float64 // Zero means no limit
+}
+
+// Width returns how far from their rating a player accepts opponents after
+// waiting for d.
+func (w Window) Width(d time.Duration) float64 {
+	width := w.Initial + w.Growth*d.Seconds()
+	if w.Max > 0 {
+		width = math.Min(width, w.Max)
+	}
+	return width
+}
+
+// QueueOptions configure a Queue.
+type QueueOptions struct {
+	Window Window
+
+	// How often the queue looks for matches as the windows widen. Players
+	// joining are matched straight away. Defaults to a second.
+	Interval time.Duration
+
+	Clock Clock // Defaults to SystemClock
+}
+
+// Queue matches players as they wait. Two players are matched once each is
+// within the other's Window. Players who have waited longest are matched
+// first, with the closest rated player that fits. It is safe for concurrent
+// use.
+type Queue struct {
+	opts QueueOptions
+
+	mu      sync.Mutex
+	waiting []Player        // Longest waiting first
+	pending map[string]bool // Players matched, but not yet sent on matches
+	closed  bool
+
+	matches chan Match
+	wake    chan struct{}
+	done    chan struct{}
+	stopped chan struct{} // closed once run has returned
+}
+
+// NewQueue creates a queue and starts matching. Close stops it.
+func NewQueue(opts QueueOptions) *Queue {
+	if opts.Interval <= 0 {
+		opts.Interval = time.Second
+	}
+	if opts.Clock == nil {
+		opts.Clock = SystemClock
+	}
+
+	q := &Queue{
+		opts:    opts,
+		pending: make(map[string]bool),
+		matches: make(chan Match),
+		wake:    make(chan struct{}, 1),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	go q.run()
+	return q
+}
+
+// Matches returns the channel matches are sent on. It is closed by Close.
+func (q *Queue) Matches() <-chan Match {
+	return q.matches
+}
+
+// Join adds a player to the queue. A zero Since means they start waiting now.
+// Players already waiting, or whose match hasn't been sent on Matches yet,
+// can't join.
+func (q *Queue) Join(p Player) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return fmt.Errorf("Queue is closed")
+	}
+	for _, w := range q.waiting {
+		if w.ID == p.ID {
+			return fmt.Errorf("Player %v is already waiting", p.ID)
+		}
+	}
+	if q.pending[p.ID] {
+		return fmt.Errorf("Player %v has a match waiting to be received", p.ID)
+	}
+	if p.Since.IsZero() {
+		p.Since = q.opts.Clock.Now()
+	}
+	q.insert(p)
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Leave removes a player from the queue, returning whether they were waiting.
+// A match that has been made can't be cancelled: Leave returns false for its
+// players, and it is sent on Matches, or undone by Close, all the same.
+func (q *Queue) Leave(id string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for i, w := range q.waiting {
+		if w.ID == id {
+			q.waiting = append(q.waiting[:i], q.waiting[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// insert adds a player to the waiting list after everyone who has waited at
+// least as long. The caller holds mu.
+func (q *Queue) insert(p Player) {
+	i := sort.Search(len(q.waiting), func(i int) bool {
+		return q.waiting[i].Since.After(p.Since)
+	})
+	q.waiting = append(q.waiting, Player{})
+	copy(q.waiting[i+1:], q.waiting[i:])
+	q.waiting[i] = p
+}
+
+// Len returns the number of waiting players.
+func (q *Queue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return len(q.waiting)
+}
+
+// Waiting returns the waiting players, longest waiting first.
+func (q *Queue) Waiting() []Player {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return append([]Player(nil), q.waiting...)
+}
+
+// Close stops matching and closes the Matches channel. Matches that were made
+// but not yet received are undone, so that Waiting returns every player who
+// didn't get a game.
+func (q *Queue) Close() {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.done)
+	}
+	q.mu.Unlock()
+
+	<-q.stopped
+}
+
+func (q *Queue) run() {
+	defer close(q.stopped)
+	defer close(q.matches)
+
+	ticker := q.opts.Clock.NewTicker(q.opts.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-q.done:
+			return
+		case <-q.wake:
+		case <-ticker.C():
+		}
+
+		matches := q.match()
+		for i, m := range matches {
+			select {
+			case q.matches <- m:
+				q.mu.Lock()
+				delete(q.pending, m.Player1.ID)
+				delete(q.pending, m.Player2.ID)
+				q.mu.Unlock()
+			case <-q.done:
+				q.requeue(matches[i:])
+				return
+			}
+		}
+	}
+}
+
+// requeue puts the players of matches that were never received back in the
+// waiting list.
+func (q *Queue) requeue(matches []Match) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, m := range matches {
+		for _, p := range []Player{m.Player1, m.Player2} {
+			delete(q.pending, p.ID)
+			q.insert(p)
+		}
+	}
+}
+
+// match takes every match that can be made now off the queue, longest waiting
+// players first.
+func (q *Queue) match() []Match {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.opts.Clock.Now()
+	paired := make([]bool, len(q.waiting))
+	var matches []Match
+	for i, p := range q.waiting {
+		if paired[i] {
+			continue
+		}
+		rating, _, _ := p.Rating.GetValues()
+		width := q.opts.Window.Width(now.Sub(p.Since))
+
+		best, bestDiff := -1, 0.0
+		for j := i + 1; j < len(q.waiting); j++ {
+			if paired[j] {
+				continue
+			}
+			opp := q.waiting[j]
+			oppRating, _, _ := opp.Rating.GetValues()
+			diff := math.Abs(rating - oppRating)
+			if diff > width || diff > q.opts.Window.Width(now.Sub(opp.Since)) {
+				continue
+			}
+			if best < 0 || diff < bestDiff {
+				best, bestDiff = j, diff
+			}
+		}
+
+		if best >= 0 {
+			paired[i], paired[best] = true, true
+			opp := q.waiting[best]
+			matches = append(matches, Match{p, opp, goglicko.MatchQuality(p.Rating, opp.Rating)})
+			q.pending[p.ID], q.pending[opp.ID] = true, true
+		}
+	}
+
+	waiting := q.waiting[:0]
+	for i, p := range q.waiting {
+		if !paired[i] {
+			waiting = append(waiting, p)
+		}
+	}
+	q.waiting = waiting
+	return matches
+}
@@ -0,0 +1,280 @@
+package matchmaking
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/clavoie/goglicko"
+)
+
+// fakeClock only moves when advanced.
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	tickers []*fakeTicker
+	created int // Number of tickers ever created
+}
+
+type fakeTicker struct {
+	clock  *fakeClock
+	period time.Duration
+	next   time.Time
+	c      chan time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) NewTicker(d time.Duration) Ticker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &fakeTicker{c, d, c.now.Add(d), make(chan time.Time, 1)}
+	c.tickers = append(c.tickers, t)
+	c.created++
+	return t
+}
+
+func (t *fakeTicker) C() <-chan time.Time { return t.c }
+
+func (t *fakeTicker) Stop() {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	for i, other := range t.clock.tickers {
+		if other == t {
+			t.clock.tickers = append(t.clock.tickers[:i], t.clock.tickers[i+1:]...)
+			return
+		}
+	}
+}
+
+// advance moves the clock on once a ticker is running, firing the tickers
+// that are due. Like a time.Ticker, a tick nobody is ready for is dropped.
+func (c *fakeClock) advance(t *testing.T, d time.Duration) {
+	for deadline := time.Now().Add(time.Second); ; time.Sleep(time.Millisecond) {
+		c.mu.Lock()
+		if len(c.tickers) > 0 {
+			break
+		}
+		c.mu.Unlock()
+		if time.Now().After(deadline) {
+			t.Fatalf("No ticker is running")
+		}
+	}
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+	for _, tk := range c.tickers {
+		if tk.next.After(c.now) {
+			continue
+		}
+		for !tk.next.After(c.now) {
+			tk.next = tk.next.Add(tk.period)
+		}
+		select {
+		case tk.c <- c.now:
+		default:
+		}
+	}
+}
+
+func receive(t *testing.T, q *Queue) Match {
+	select {
+	case m := <-q.Matches():
+		return m
+	case <-time.After(time.Second):
+		t.Fatalf("No match made")
+	}
+	return Match{}
+}
+
+func TestQueueWidens(t *testing.T) {
+	clock := &fakeClock{now: now}
+	q := NewQueue(QueueOptions{Window: Window{Initial: 100, Growth: 10, Max: 400}, Clock: clock})
+	defer q.Close()
+
+	if err := q.Join(player("a", 1500, 0)); err != nil {
+		t.Fatalf("Error while joining: %v", err)
+	}
+	if err := q.Join(Player{ID: "b", Rating: goglicko.NewRating(1650, 50, goglicko.DefaultVol, sys)}); err != nil {
+		t.Fatalf("Error while joining: %v", err)
+	}
+	if err := q.Join(player("a", 1500, 0)); err == nil {
+		t.Errorf("Expected an error for joining twice")
+	}
+
+	// 150 points apart: matched once both windows have widened past 150.
+	for i := 0; i < 4; i++ {
+		clock.advance(t, time.Second)
+	}
+	select {
+	case m := <-q.Matches():
+		t.Fatalf("Matched too early: %+v", m)
+	default:
+	}
+	if q.Len() != 2 {
+		t.Errorf("%v players waiting, expected 2", q.Len())
+	}
+
+	clock.advance(t, time.Second)
+	m := receive(t, q)
+	if m.Player1.ID != "a" || m.Player2.ID != "b" || !m.Player2.Since.Equal(now) || m.Quality <= 0 {
+		t.Errorf("Match %+v", m)
+	}
+	if q.Len() != 0 {
+		t.Errorf("%v players waiting after the match", q.Len())
+	}
+}
+
+func TestQueueClosestFirst(t *testing.T) {
+	clock := &fakeClock{now: now}
+	q := NewQueue(QueueOptions{Window: Window{Initial: 200}, Clock: clock})
+	defer q.Close()
+
+	for _, p := range []Player{player("a", 1500, time.Minute), player("b", 1680, 0), player("c", 1450, 0)} {
+		if err := q.Join(p); err != nil {
+			t.Fatalf("Error while joining: %v", err)
+		}
+	}
+	clock.advance(t, time.Second)
+	if m := receive(t, q); m.Player1.ID != "a" || m.Player2.ID != "c" {
+		t.Errorf("Matched %v with %v, expected a with c", m.Player1.ID, m.Player2.ID)
+	}
+	if !q.Leave("b") || q.Leave("b") {
+		t.Errorf("Expected b to leave once")
+	}
+}
+
+func TestQueueLongestWaitingFirst(t *testing.T) {
+	clock := &fakeClock{now: now}
+	q := NewQueue(QueueOptions{Window: Window{Initial: 60}, Clock: clock})
+	defer q.Close()
+
+	// x joined first, but y has waited longer and gets z, who fits both.
+	for _, p := range []Player{player("x", 1500, 0), player("y", 1600, time.Minute)} {
+		if err := q.Join(p); err != nil {
+			t.Fatalf("Error while joining: %v", err)
+		}
+	}
+	if w := q.Waiting(); len(w) != 2 || w[0].ID != "y" || w[1].ID != "x" {
+		t.Errorf("Waiting %v, expected y first", w)
+	}
+	if err := q.Join(player("z", 1550, 0)); err != nil {
+		t.Fatalf("Error while joining: %v", err)
+	}
+	if m := receive(t, q); m.Player1.ID != "y" || m.Player2.ID != "z" {
+		t.Errorf("Matched %v with %v, expected y with z", m.Player1.ID, m.Player2.ID)
+	}
+}
+
+func TestQueueOneTicker(t *testing.T) {
+	clock := &fakeClock{now: now}
+	q := NewQueue(QueueOptions{Window: Window{Initial: 10}, Clock: clock})
+	q.Join(player("a", 1500, 0))
+	q.Join(player("b", 1800, 0))
+	for i := 0; i < 10; i++ {
+		clock.advance(t, time.Second)
+	}
+	q.Close()
+
+	clock.mu.Lock()
+	defer clock.mu.Unlock()
+	if clock.created != 1 || len(clock.tickers) != 0 {
+		t.Errorf("%v tickers created, %v left running; expected one, stopped", clock.created, len(clock.tickers))
+	}
+}
+
+func TestQueueCloseUndoesMatches(t *testing.T) {
+	q := NewQueue(QueueOptions{Window: Window{Initial: 1000}, Clock: &fakeClock{now: now}})
+	for _, id := range []string{"a", "b", "c", "d"} {
+		if err := q.Join(player(id, 1500, 0)); err != nil {
+			t.Fatalf("Error while joining: %v", err)
+		}
+	}
+
+	// Wait for the matches to be made, then close without receiving them.
+	for deadline := time.Now().Add(time.Second); q.Len() > 0; time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("%v players still waiting", q.Len())
+		}
+	}
+	q.Close()
+
+	if w := q.Waiting(); len(w) != 4 {
+		t.Errorf("Waiting %v after closing, expected every player back", w)
+	}
+}
+
+func TestQueuePendingMatch(t *testing.T) {
+	q := NewQueue(QueueOptions{Window: Window{Initial: 1000}, Clock: &fakeClock{now: now}})
+	for _, id := range []string{"a", "b", "c", "d"} {
+		if err := q.Join(player(id, 1500, 0)); err != nil {
+			t.Fatalf("Error while joining: %v", err)
+		}
+	}
+	for deadline := time.Now().Add(time.Second); q.Len() > 0; time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("%v players still waiting", q.Len())
+		}
+	}
+
+	// Until a match is received, its players can neither join again nor
+	// leave it.
+	m := receive(t, q)
+	pending := "a"
+	if m.Player1.ID == "a" || m.Player2.ID == "a" {
+		pending = "c"
+		if m.Player1.ID == "c" || m.Player2.ID == "c" {
+			pending = "b"
+		}
+	}
+	if err := q.Join(player(pending, 1500, 0)); err == nil {
+		t.Errorf("Expected an error joining %v, whose match wasn't received", pending)
+	}
+	if q.Leave(pending) {
+		t.Errorf("%v left a match that was made", pending)
+	}
+
+	// Players whose match was received can join again.
+	for deadline := time.Now().Add(time.Second); q.Join(player(m.Player1.ID, 2500, 0)) != nil; time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("%v can't join again after their match was received", m.Player1.ID)
+		}
+	}
+
+	q.Close()
+	w := q.Waiting()
+	seen := make(map[string]bool)
+	for _, p := range w {
+		if seen[p.ID] {
+			t.Errorf("%v is waiting twice: %v", p.ID, w)
+		}
+		seen[p.ID] = true
+	}
+	if len(w) != 3 || !seen[pending] || !seen[m.Player1.ID] {
+		t.Errorf("Waiting %v after closing, expected %v, their opponent and %v", w, pending, m.Player1.ID)
+	}
+}
+
+func TestQueueClose(t *testing.T) {
+	q := NewQueue(QueueOptions{Clock: &fakeClock{now: now}})
+	q.Close()
+	q.Close()
+
+	if _, ok := <-q.Matches(); ok {
+		t.Errorf("Matches not closed")
+	}
+	if err := q.Join(player("a", 1500, 0)); err == nil {
+		t.Errorf("Expected an error joining a closed queue")
+	}
+}
+
+func TestWindowWidth(t *testing.T) {
+	w := Window{Initial: 50, Growth: 5, Max: 100}
+	if w.Width(0) != 50 || w.Width(4*time.Second) != 70 || w.Width(time.Minute) != 100 {
+		t.Errorf("Widths %v, %v, %v", w.Width(0), w.Width(4*time.Second), w.Width(time.Minute))
+	}
+}
@@ -0,0 +1,100 @@
+package goglicko
+
+import (
+	"fmt"
+	"math"
+)
+
+// Performance is the rating a player performed at over a set of games.
+type Performance struct {
+	Rating float64
+
+	// Uncertainty of the performance rating: the deviation a rating would
+	// have if these games were all that was known about the player.
+	Deviation float64
+
+	Score float64 // Points scored
+	Games int
+}
+
+// PerformanceRating returns the rating whose expected score against the
+// opponents equals the score of res, treating the opponents' ratings as exact.
+// A perfect or zero score has no finite performance rating and is an error.
+func PerformanceRating(opponents []*Rating, res []Result) (float64, error) {
+	p, err := performance(opponents, res, false)
+	if err != nil {
+		return 0, err
+	}
+	return p.Rating, nil
+}
+
+// GlickoPerformance is PerformanceRating under the Glicko model: each
+// opponent's expected score is flattened by their deviation, as in Update, so
+// results against uncertain opponents move the performance rating further.
+func GlickoPerformance(opponents []*Rating, res []Result) (*Performance, error) {
+	return performance(opponents, res, true)
+}
+
+func performance(opponents []*Rating, res []Result, deviationAware bool) (*Performance, error) {
+	if len(opponents) != len(res) {
+		return nil, fmt.Errorf("Number of opponents must == number of results. %v != %v",
+			len(opponents), len(res))
+	}
+	if len(opponents) == 0 {
+		return nil, fmt.Errorf("No games to compute a performance rating from")
+	}
+
+	// Ratings are compared on the Glicko2 scale, less any base rating, which
+	// cancels out of every expected score.
+	mus := make([]float64, len(opponents))
+	devs := make([]float64, len(opponents))
+	score := 0.0
+	for i, o := range opponents {
+		mus[i] = o.rating / glicko2Scale
+		if deviationAware {
+			devs[i] = o.deviation / glicko2Scale
+		}
+		score += float64(res[i])
+	}
+	if score <= 0 || score >= float64(len(res)) {
+		return nil, fmt.Errorf("A score of %v out of %v has no performance rating", score, len(res))
+	}
+
+	expected := func(mu float64) float64 {
+		sum := 0.0
+		for i := range mus {
+			sum += ee(mu, mus[i], devs[i])
+		}
+		return sum
+	}
+
+	// The expected score rises with the rating, so bisect from far enough
+	// below and above every opponent.
+	lo, hi := mus[0], mus[0]
+	for _, mu := range mus {
+		lo, hi = math.Min(lo, mu), math.Max(hi, mu)
+	}
+	lo, hi = lo-50, hi+50
+	for i := 0; i < 200 && hi-lo > 1e-12; i++ {
+		mid := (lo + hi) / 2
+		if expected(mid) < score {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	mu := (lo + hi) / 2
+
+	info := 0.0
+	for i := range mus {
+		e := ee(mu, mus[i], devs[i])
+		info += sq(gee(devs[i])) * e * (1 - e)
+	}
+
+	return &Performance{
+		Rating:    mu * glicko2Scale,
+		Deviation: glicko2Scale / math.Sqrt(info),
+		Score:     score,
+		Games:     len(res),
+	}, nil
+}
@@ -0,0 +1,76 @@
+package goglicko
+
+import "testing"
+
+func TestPerformanceRating(t *testing.T) {
+	sys := NewDefaultSystem()
+	opps := []*Rating{
+		NewRating(1400, 30, DefaultVol, sys),
+		NewRating(1500, 100, DefaultVol, sys),
+		NewRating(1600, 300, DefaultVol, sys),
+	}
+
+	// Half the points against a symmetric field is a performance at its middle.
+	rating, err := PerformanceRating(opps, []Result{Win, Draw, Loss})
+	if err != nil {
+		t.Fatalf("Error while computing: %v", err)
+	}
+	if !floatsMostlyEqual(rating, 1500, 1e-6) {
+		t.Errorf("Performance rating %v, expected 1500", rating)
+	}
+
+	res := []Result{Win, Win, Loss}
+	rating, err = PerformanceRating(opps, res)
+	if err != nil {
+		t.Fatalf("Error while computing: %v", err)
+	}
+	sum := 0.0
+	for _, o := range opps {
+		sum += ExpectedScore(NewRating(rating, 0, DefaultVol, sys), NewRating(o.rating, 0, DefaultVol, sys))
+	}
+	if !floatsMostlyEqual(sum, 2, 1e-9) {
+		t.Errorf("Performance rating %v is expected to score %v, not 2", rating, sum)
+	}
+
+	p, err := GlickoPerformance(opps, res)
+	if err != nil {
+		t.Fatalf("Error while computing: %v", err)
+	}
+	sum = 0.0
+	for _, o := range opps {
+		sum += ExpectedScore(NewRating(p.Rating, 0, DefaultVol, sys), o)
+	}
+	if !floatsMostlyEqual(sum, 2, 1e-9) || p.Score != 2 || p.Games != 3 {
+		t.Errorf("Glicko performance %+v is expected to score %v, not 2", p, sum)
+	}
+	if p.Rating <= rating {
+		t.Errorf("Beating an uncertain opponent should count for more: %v <= %v", p.Rating, rating)
+	}
+	if p.Deviation <= 100 {
+		t.Errorf("Deviation %v is too certain for 3 games", p.Deviation)
+	}
+}
+
+func TestPerformanceRatingErrors(t *testing.T) {
+	opp := NewDefaultRating()
+	tests := []struct {
+		name string
+		opps []*Rating
+		res  []Result
+	}{
+		{"no games", nil, nil},
+		{"mismatched", []*Rating{opp}, nil},
+		{"perfect score", []*Rating{opp, opp}, []Result{Win, Win}},
+		{"zero score", []*Rating{opp}, []Result{Loss}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := PerformanceRating(test.opps, test.res); err == nil {
+				t.Errorf("Expected an error")
+			}
+			if _, err := GlickoPerformance(test.opps, test.res); err == nil {
+				t.Errorf("Expected an error")
+			}
+		})
+	}
+}
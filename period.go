@@ -0,0 +1,104 @@
+package goglicko
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// GroupPeriods splits games into consecutive rating periods of the given
+// length, the first starting at origin. Periods in which nobody played are
+// included, since sitting out a period still changes a player's deviation.
+// Periods are numbered from 1.
+func GroupPeriods(games []*Game, origin time.Time, length time.Duration) ([]*Period, error) {
+	if length <= 0 {
+		return nil, fmt.Errorf("Period length must be positive, was %v", length)
+	}
+
+	sorted := append([]*Game(nil), games...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Played.Before(sorted[j].Played)
+	})
+
+	var periods []*Period
+	for _, g := range sorted {
+		if g.Played.Before(origin) {
+			return nil, fmt.Errorf("Game %v was played at %v, before the first period starts at %v",
+				g.ID, g.Played, origin)
+		}
+
+		index := int(g.Played.Sub(origin) / length)
+		for len(periods) <= index {
+			start := origin.Add(time.Duration(len(periods)) * length)
+			periods = append(periods, &Period{
+				ID:    int64(len(periods) + 1),
+				Start: start,
+				End:   start.Add(length),
+			})
+		}
+		periods[index].Games = append(periods[index].Games, g)
+	}
+
+	return periods, nil
+}
+
+// RatePeriod updates ratings with the games of a rating period. Every game is
+// rated against the opponents' ratings from before the period. Players seen for
+// the first time are added with the starting rating of sys, and players who
+// didn't play have their deviation grown. A game's Advantage is applied by
+// shifting the opponent's rating for that game only. Ratings with a History
+// record a snapshot at the end of the period.
+func RatePeriod(ratings map[string]*Rating, p *Period, sys *System) error {
+	for _, g := range p.Games {
+		if err := g.Validate(); err != nil {
+			return err
+		}
+		for _, player := range []string{g.Player1, g.Player2} {
+			if _, ok := ratings[player]; !ok {
+				ratings[player] = sys.NewRating()
+			}
+		}
+	}
+
+	// Only the players of the period are needed as opponents.
+	before := make(map[string]*Rating)
+	for _, g := range p.Games {
+		for _, player := range []string{g.Player1, g.Player2} {
+			if _, ok := before[player]; !ok {
+				before[player] = ratings[player].Copy()
+			}
+		}
+	}
+
+	opponents := make(map[string][]*Rating)
+	results := make(map[string][]Result)
+	weights := make(map[string][]float64)
+	for _, g := range p.Games {
+		opponents[g.Player1] = append(opponents[g.Player1], shifted(before[g.Player2], -g.Advantage))
+		results[g.Player1] = append(results[g.Player1], g.Result)
+		weights[g.Player1] = append(weights[g.Player1], g.Weight)
+		opponents[g.Player2] = append(opponents[g.Player2], shifted(before[g.Player1], g.Advantage))
+		results[g.Player2] = append(results[g.Player2], g.Result.Opposite())
+		weights[g.Player2] = append(weights[g.Player2], g.Weight)
+	}
+
+	for player, r := range ratings {
+		err := r.updateAt(p.End, opponents[player], results[player], weights[player])
+		if err != nil {
+			return fmt.Errorf("Could not rate %v: %v", player, err)
+		}
+	}
+
+	return nil
+}
+
+// shifted returns r moved by points, or r itself if there is nothing to move.
+func shifted(r *Rating, points float64) *Rating {
+	if points == 0 {
+		return r
+	}
+
+	s := r.Copy()
+	s.rating += points
+	return s
+}
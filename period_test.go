@@ -0,0 +1,102 @@
+package goglicko
+
+import (
+	"testing"
+	"time"
+)
+
+var periodOrigin = time.Date(2017, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+func TestGroupPeriods(t *testing.T) {
+	week := 7 * 24 * time.Hour
+	games := []*Game{
+		{ID: 1, Player1: "a", Player2: "b", Result: Win, Played: periodOrigin.Add(3 * week), Weight: 1},
+		{ID: 2, Player1: "a", Player2: "c", Result: Draw, Played: periodOrigin, Weight: 1},
+		{ID: 3, Player1: "b", Player2: "c", Result: Loss, Played: periodOrigin.Add(week - 1), Weight: 1},
+	}
+
+	periods, err := GroupPeriods(games, periodOrigin, week)
+	if err != nil {
+		t.Fatalf("Error while grouping: %v", err)
+	}
+
+	expGames := [][]int64{{2, 3}, {}, {}, {1}}
+	if len(periods) != len(expGames) {
+		t.Fatalf("len(periods) %v != %v", len(periods), len(expGames))
+	}
+	for i, p := range periods {
+		if p.ID != int64(i+1) || !p.Start.Equal(periodOrigin.Add(time.Duration(i)*week)) {
+			t.Errorf("Period %v has id %v and start %v", i, p.ID, p.Start)
+		}
+		if len(p.Games) != len(expGames[i]) {
+			t.Errorf("Period %v has games %v, expected ids %v", i, p.Games, expGames[i])
+			continue
+		}
+		for j, g := range p.Games {
+			if g.ID != expGames[i][j] {
+				t.Errorf("Period %v game %v has id %v != %v", i, j, g.ID, expGames[i][j])
+			}
+		}
+	}
+
+	if _, err := GroupPeriods(games, periodOrigin.Add(week), week); err == nil {
+		t.Errorf("Expected an error for games before the origin")
+	}
+}
+
+func TestRatePeriod(t *testing.T) {
+	sys := NewDefaultSystem()
+	ratings := map[string]*Rating{
+		"pl":   NewRating(1500, 200, DefaultVol, sys),
+		"o1":   NewRating(1400, 30, DefaultVol, sys),
+		"o2":   NewRating(1550, 100, DefaultVol, sys),
+		"o3":   NewRating(1700, 300, DefaultVol, sys),
+		"idle": NewRating(1500, 200, DefaultVol, sys),
+	}
+	p := &Period{ID: 1, Start: periodOrigin, End: periodOrigin.Add(time.Hour), Games: []*Game{
+		{Player1: "pl", Player2: "o1", Result: Win, Weight: 1},
+		{Player1: "o2", Player2: "pl", Result: Win, Weight: 1},
+		{Player1: "pl", Player2: "o3", Result: Loss, Weight: 1},
+		{Player1: "new", Player2: "o1", Result: Loss, Weight: 1},
+	}}
+
+	if err := RatePeriod(ratings, p, sys); err != nil {
+		t.Fatalf("Error while rating: %v", err)
+	}
+
+	pl := ratings["pl"]
+	if !floatsMostlyEqual(pl.rating, 1464.06, 0.01) || !floatsMostlyEqual(pl.deviation, 151.52, 0.01) {
+		t.Errorf("pl %v doesn't match the Glicko2 paper", pl)
+	}
+
+	idle := ratings["idle"]
+	if idle.rating != 1500 || !floatsMostlyEqual(idle.deviation, 200.27, 0.01) {
+		t.Errorf("idle %v should only have grown its deviation", idle)
+	}
+
+	if _, ok := ratings["new"]; !ok {
+		t.Errorf("New player wasn't added")
+	}
+}
+
+func TestRatePeriodWeights(t *testing.T) {
+	sys := NewDefaultSystem()
+	rate := func(weight float64) *Rating {
+		ratings := map[string]*Rating{}
+		p := &Period{Games: []*Game{{Player1: "a", Player2: "b", Result: Win, Weight: weight}}}
+		if err := RatePeriod(ratings, p, sys); err != nil {
+			t.Fatalf("Error while rating: %v", err)
+		}
+		return ratings["a"]
+	}
+
+	for _, weight := range []float64{0, -1} {
+		p := &Period{Games: []*Game{{Player1: "a", Player2: "b", Result: Win, Weight: weight}}}
+		if err := RatePeriod(map[string]*Rating{}, p, sys); err == nil {
+			t.Errorf("Expected an error for weight %v", weight)
+		}
+	}
+	if half, one := rate(0.5), rate(1); half.rating >= one.rating {
+		t.Errorf("A half weighted win %v gained as much as a full one %v", half, one)
+	}
+}
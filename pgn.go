@@ -0,0 +1,225 @@
+package goglicko
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PGNGame is the header of a game from a PGN archive. The moves aren't kept.
+type PGNGame struct {
+	White   string
+	Black   string
+	Date    time.Time // Zero if the date is unknown. Unknown months and days are taken as the 1st.
+	Result  string    // The Result tag: 1-0, 0-1, 1/2-1/2, * or a forfeit marker
+	Forfeit bool      // The game wasn't played out, but awarded by forfeit
+	Tags    map[string]string
+	Line    int // Line of the archive the game starts on
+}
+
+// Game converts the PGN game into a Game rated from White's point of view.
+// Unfinished and double forfeited games can't be rated.
+func (pg *PGNGame) Game() (*Game, error) {
+	g := &Game{Player1: pg.White, Player2: pg.Black, Played: pg.Date, Weight: 1}
+	switch pg.Result {
+	case "1-0", "+/-", "+--":
+		g.Result = Win
+	case "0-1", "-/+", "--+":
+		g.Result = Loss
+	case "1/2-1/2", "=/=":
+		g.Result = Draw
+	default:
+		return nil, fmt.Errorf("Can't rate a game with result %q", pg.Result)
+	}
+	return g, g.Validate()
+}
+
+// PGNReader reads game headers out of a PGN archive, one game at a time. Only
+// the current line is held in memory, so archives of any size can be read.
+type PGNReader struct {
+	r       *bufio.Reader
+	line    int
+	pending *PGNGame // game whose tags have been read, waiting for its moves
+}
+
+// NewPGNReader creates a reader over a PGN archive.
+func NewPGNReader(r io.Reader) *PGNReader {
+	return &PGNReader{r: bufio.NewReaderSize(r, 64*1024)}
+}
+
+// readLine returns the next line without its line ending.
+func (r *PGNReader) readLine() (string, error) {
+	line, err := r.r.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = nil
+	}
+	if err != nil {
+		return "", err
+	}
+	r.line++
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// Next returns the next game, or io.EOF once the archive is exhausted.
+func (r *PGNReader) Next() (*PGNGame, error) {
+	g := r.pending
+	r.pending = nil
+	inMoves := false
+	comment := false // inside a {} comment, which may span lines
+
+	for {
+		line, err := r.readLine()
+		if err == io.EOF {
+			if g == nil {
+				return nil, io.EOF
+			}
+			return g.finish(), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		trimmed := strings.TrimSpace(line)
+		if !comment && strings.HasPrefix(trimmed, "[") {
+			if g != nil && inMoves {
+				// The tags of the next game: the current one is done.
+				r.pending = &PGNGame{Tags: map[string]string{}, Line: r.line}
+				if err := r.pending.parseTag(trimmed); err != nil {
+					return nil, fmt.Errorf("Line %v: %v", r.line, err)
+				}
+				return g.finish(), nil
+			}
+			if g == nil {
+				g = &PGNGame{Tags: map[string]string{}, Line: r.line}
+			}
+			if err := g.parseTag(trimmed); err != nil {
+				return nil, fmt.Errorf("Line %v: %v", r.line, err)
+			}
+			continue
+		}
+
+		if trimmed == "" || strings.HasPrefix(trimmed, "%") || (!comment && strings.HasPrefix(trimmed, ";")) {
+			continue
+		}
+		if g == nil {
+			return nil, fmt.Errorf("Line %v: moves before any tags", r.line)
+		}
+		inMoves = true
+		for _, c := range trimmed {
+			if c == '{' {
+				comment = true
+			} else if c == '}' {
+				comment = false
+			} else if c == ';' && !comment {
+				break // the rest of the line is a comment, braces and all
+			}
+		}
+	}
+}
+
+// parseTag parses a tag pair such as [White "Carlsen, Magnus"].
+func (pg *PGNGame) parseTag(s string) error {
+	if !strings.HasSuffix(s, "]") {
+		return fmt.Errorf("malformed tag %v", s)
+	}
+	s = strings.TrimSpace(s[1 : len(s)-1])
+
+	space := strings.IndexAny(s, " \t")
+	if space < 0 {
+		return fmt.Errorf("malformed tag [%v]", s)
+	}
+	name := s[:space]
+	value, err := strconv.Unquote(strings.TrimSpace(s[space:]))
+	if err != nil {
+		return fmt.Errorf("malformed value in tag [%v]", s)
+	}
+
+	pg.Tags[name] = value
+	return nil
+}
+
+// finish fills in the fields of the game from its tags.
+func (pg *PGNGame) finish() *PGNGame {
+	pg.White = pg.Tags["White"]
+	pg.Black = pg.Tags["Black"]
+	pg.Result = pg.Tags["Result"]
+	pg.Date = parsePGNDate(pg.Tags["Date"])
+	if pg.Date.IsZero() {
+		pg.Date = parsePGNDate(pg.Tags["EventDate"])
+	}
+
+	switch strings.ToLower(pg.Tags["Termination"]) {
+	case "forfeit", "unplayed":
+		pg.Forfeit = true
+	}
+	switch pg.Result {
+	case "+/-", "-/+", "+--", "--+", "-/-", "--", "=/=":
+		pg.Forfeit = true
+	}
+	return pg
+}
+
+// parsePGNDate parses a PGN date, YYYY.MM.DD, where unknown parts are written
+// as question marks.
+func parsePGNDate(s string) time.Time {
+	parts := strings.Split(s, ".")
+	if len(parts) != 3 {
+		return time.Time{}
+	}
+
+	year, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return time.Time{}
+	}
+	month, err := strconv.Atoi(parts[1])
+	if err != nil {
+		month = 1
+	}
+	day, err := strconv.Atoi(parts[2])
+	if err != nil {
+		day = 1
+	}
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
+
+// ReadPGNGames reads every rateable game of a PGN archive, numbering them from
+// 1, ready to be grouped into periods by NewReplay. Unfinished games are
+// skipped, as are forfeits unless forfeits is set. Games that can't be rated,
+// such as those without a date, which can't be placed in a rating period, or
+// with a missing player or an unknown result, are skipped too, and returned as
+// *LineErrors for the line their tags start on.
+func ReadPGNGames(r io.Reader, forfeits bool) ([]*Game, []*LineError, error) {
+	pr := NewPGNReader(r)
+	var games []*Game
+	var lineErrs []*LineError
+	for {
+		pg, err := pr.Next()
+		if err == io.EOF {
+			return games, lineErrs, nil
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if pg.Result == "*" || pg.Result == "" || (pg.Forfeit && !forfeits) {
+			continue
+		}
+		g, err := pg.Game()
+		if err != nil {
+			if !pg.Forfeit { // double forfeits have no winner to rate
+				lineErrs = append(lineErrs, &LineError{pg.Line, err})
+			}
+			continue
+		}
+		if g.Played.IsZero() {
+			lineErrs = append(lineErrs, &LineError{pg.Line, fmt.Errorf("Game has no date")})
+			continue
+		}
+
+		g.ID = int64(len(games) + 1)
+		games = append(games, g)
+	}
+}
@@ -0,0 +1,180 @@
+package goglicko
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testPGN = `[Event "Club Championship"]
+[Site "?"]
+[Date "2017.03.01"]
+[White "Alice"]
+[Black "Bob \"The Rook\""]
+[Result "1-0"]
+
+1. e4 e5 2. Nf3 {A comment
+[that looks like a tag]} Nc6 3. Bb5 1-0
+
+[Event "Club Championship"]
+[Date "2017.03.??"]
+[White "Carol"]
+[Black "Alice"]
+[Result "1/2-1/2"]
+
+1. d4 d5 1/2-1/2
+
+[Event "Club Championship"]
+[Date "2017.03.08"]
+[White "Bob \"The Rook\""]
+[Black "Carol"]
+[Result "0-1"]
+[Termination "forfeit"]
+
+0-1
+
+[Event "Club Championship"]
+[Date "2017.03.08"]
+[White "Alice"]
+[Black "Dave"]
+[Result "*"]
+
+1. c4 *
+`
+
+func TestPGNReader(t *testing.T) {
+	r := NewPGNReader(strings.NewReader(testPGN))
+
+	var games []*PGNGame
+	for {
+		g, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Error while reading: %v", err)
+		}
+		games = append(games, g)
+	}
+	if len(games) != 4 {
+		t.Fatalf("Read %v games, expected 4", len(games))
+	}
+
+	if g := games[0]; g.White != "Alice" || g.Black != `Bob "The Rook"` || g.Result != "1-0" {
+		t.Errorf("First game read as %+v", g)
+	}
+	if d := games[1].Date; !d.Equal(time.Date(2017, time.March, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Partial date read as %v", d)
+	}
+	if !games[2].Forfeit || games[0].Forfeit {
+		t.Errorf("Forfeits not marked: %v, %v", games[2].Forfeit, games[0].Forfeit)
+	}
+	if games[3].Line != 28 {
+		t.Errorf("Last game starts on line %v, expected 28", games[3].Line)
+	}
+}
+
+func TestReadPGNGames(t *testing.T) {
+	games, lineErrs, err := ReadPGNGames(strings.NewReader(testPGN), false)
+	if err != nil || len(lineErrs) != 0 {
+		t.Fatalf("Error while reading: %v, %v", err, lineErrs)
+	}
+	if len(games) != 2 || games[0].Result != Win || games[1].Result != Draw {
+		t.Errorf("Read games %v", games)
+	}
+
+	games, lineErrs, err = ReadPGNGames(strings.NewReader(testPGN), true)
+	if err != nil || len(lineErrs) != 0 {
+		t.Fatalf("Error while reading: %v, %v", err, lineErrs)
+	}
+	if len(games) != 3 || games[2].Player1 != `Bob "The Rook"` || games[2].Result != Loss {
+		t.Errorf("Read games with forfeits %v", games)
+	}
+}
+
+func TestPGNLineComment(t *testing.T) {
+	pgn := `[White "Alice"]
+[Black "Bob"]
+[Date "2017.03.01"]
+[Result "1-0"]
+
+1. e4 ; see {note
+e5 1-0
+
+[White "Carol"]
+[Black "Alice"]
+[Date "2017.03.02"]
+[Result "0-1"]
+
+1. d4 0-1
+`
+	games, lineErrs, err := ReadPGNGames(strings.NewReader(pgn), false)
+	if err != nil || len(lineErrs) != 0 {
+		t.Fatalf("Error while reading: %v, %v", err, lineErrs)
+	}
+	if len(games) != 2 || games[1].Player1 != "Carol" {
+		t.Errorf("Brace in a ; comment hid the next game: %v", games)
+	}
+}
+
+func TestReadPGNGamesUndated(t *testing.T) {
+	pgn := `[White "Alice"]
+[Black "Bob"]
+[Date "????.??.??"]
+[Result "1-0"]
+
+1. e4 1-0
+
+[White "Carol"]
+[Black "Alice"]
+[Date "2017.03.02"]
+[Result "0-1"]
+
+1. d4 0-1
+`
+	games, lineErrs, err := ReadPGNGames(strings.NewReader(pgn), false)
+	if err != nil {
+		t.Fatalf("Error while reading: %v", err)
+	}
+	if len(games) != 1 || games[0].Player1 != "Carol" || games[0].ID != 1 {
+		t.Errorf("Read games %v, expected Carol's game only", games)
+	}
+	if len(lineErrs) != 1 || lineErrs[0].Line != 1 {
+		t.Errorf("Undated game reported as %v, expected line 1", lineErrs)
+	}
+}
+
+func TestReadPGNGamesInvalid(t *testing.T) {
+	pgn := `[White ""]
+[Black "Bob"]
+[Date "2017.03.01"]
+[Result "1-0"]
+
+1. e4 1-0
+
+[White "Alice"]
+[Black "Bob"]
+[Date "2017.03.01"]
+[Result "2-0"]
+
+1. e4 2-0
+
+[White "Carol"]
+[Black "Alice"]
+[Date "2017.03.02"]
+[Result "0-1"]
+
+1. d4 0-1
+`
+	games, lineErrs, err := ReadPGNGames(strings.NewReader(pgn), false)
+	if err != nil {
+		t.Fatalf("Error while reading: %v", err)
+	}
+	if len(games) != 1 || games[0].Player1 != "Carol" {
+		t.Errorf("Read games %v, expected Carol's game only", games)
+	}
+	if len(lineErrs) != 2 || lineErrs[0].Line != 1 || lineErrs[1].Line != 8 {
+		t.Errorf("Invalid games reported as %v, expected lines 1 and 8", lineErrs)
+	}
+}
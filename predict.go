@@ -0,0 +1,73 @@
+package goglicko
+
+import (
+	"fmt"
+	"math"
+)
+
+// ExpectedScore returns the expected score of a against b: the probability of
+// a win, with draws counting half. The uncertainty in both ratings is
+// accounted for, so the less certain the ratings, the closer it is to 0.5.
+func ExpectedScore(a, b *Rating) float64 {
+	a2, b2 := a.toGlicko2(), b.toGlicko2()
+	return ee(a2.rating, b2.rating, math.Sqrt(sq(a2.deviation)+sq(b2.deviation)))
+}
+
+// Outcome is one possible result of a game and what it would do to the
+// ratings of both players.
+type Outcome struct {
+	Result      Result  // From the first player's point of view
+	Probability float64 // Chance of the result
+	Change      float64 // Rating change of the first player
+	OppChange   float64 // Rating change of the second player
+}
+
+// Prediction forecasts a game between two players.
+type Prediction struct {
+	Expected float64    // Expected score of the first player
+	Outcomes [3]Outcome // Win, Draw and Loss for the first player, in that order
+}
+
+// Predict forecasts a game of a against b. drawRate is the chance of a draw
+// between equally matched players, between 0 and 1; draws get less likely the
+// more one player is favoured. Rating changes are those of a rating period
+// containing only this game.
+func Predict(a, b *Rating, drawRate float64) (*Prediction, error) {
+	if drawRate < 0 || drawRate > 1 {
+		return nil, fmt.Errorf("Draw rate must be between 0 and 1, was %v", drawRate)
+	}
+
+	e := ExpectedScore(a, b)
+	draw := drawRate * (1 - math.Abs(2*e-1))
+	p := &Prediction{Expected: e}
+	for i, res := range []Result{Win, Draw, Loss} {
+		newA, newB := a.Copy(), b.Copy()
+		if err := newA.Update([]*Rating{b}, []Result{res}); err != nil {
+			return nil, err
+		}
+		if err := newB.Update([]*Rating{a}, []Result{res.Opposite()}); err != nil {
+			return nil, err
+		}
+
+		p.Outcomes[i] = Outcome{
+			Result:    res,
+			Change:    newA.rating - a.rating,
+			OppChange: newB.rating - b.rating,
+		}
+	}
+	p.Outcomes[0].Probability = e - draw/2
+	p.Outcomes[1].Probability = draw
+	p.Outcomes[2].Probability = 1 - e - draw/2
+
+	return p, nil
+}
+
+// MatchQuality returns how good a game between a and b would be, between 0
+// and 1. An even game between players whose ratings are certain scores 1; the
+// quality falls as either player becomes the favourite, and as the deviations
+// grow, since an even game between uncertain ratings is only even on paper.
+func MatchQuality(a, b *Rating) float64 {
+	a2, b2 := a.toGlicko2(), b.toGlicko2()
+	e := ee(a2.rating, b2.rating, 0)
+	return (1 - math.Abs(2*e-1)) * gee(math.Sqrt(sq(a2.deviation)+sq(b2.deviation)))
+}
@@ -0,0 +1,69 @@
+package goglicko
+
+import "testing"
+
+func TestExpectedScore(t *testing.T) {
+	sys := NewDefaultSystem()
+	a := NewRating(1700, 50, DefaultVol, sys)
+	b := NewRating(1500, 50, DefaultVol, sys)
+
+	e := ExpectedScore(a, b)
+	if e <= 0.5 || !floatsMostlyEqual(e+ExpectedScore(b, a), 1, 1e-9) {
+		t.Errorf("ExpectedScore %v not symmetric or not favouring a", e)
+	}
+
+	uncertain := NewRating(1700, 350, DefaultVol, sys)
+	if ExpectedScore(uncertain, b) >= e {
+		t.Errorf("An uncertain rating should be expected to score less")
+	}
+}
+
+func TestPredict(t *testing.T) {
+	sys := NewDefaultSystem()
+	a := NewRating(1600, 100, DefaultVol, sys)
+	b := NewRating(1500, 150, DefaultVol, sys)
+
+	p, err := Predict(a, b, 0.3)
+	if err != nil {
+		t.Fatalf("Error while predicting: %v", err)
+	}
+
+	win, draw, loss := p.Outcomes[0], p.Outcomes[1], p.Outcomes[2]
+	if !floatsMostlyEqual(win.Probability+draw.Probability+loss.Probability, 1, 1e-9) {
+		t.Errorf("Probabilities don't sum to 1: %+v", p)
+	}
+	if !floatsMostlyEqual(win.Probability+draw.Probability/2, p.Expected, 1e-9) {
+		t.Errorf("Probabilities don't match the expected score: %+v", p)
+	}
+	if win.Probability <= loss.Probability || draw.Probability >= 0.3 {
+		t.Errorf("Probabilities don't favour the stronger player: %+v", p)
+	}
+	if win.Change <= 0 || win.OppChange >= 0 || loss.Change >= 0 || draw.Change >= 0 {
+		t.Errorf("Unexpected rating changes: %+v", p)
+	}
+	if a.rating != 1600 || b.rating != 1500 {
+		t.Errorf("Predict changed the ratings")
+	}
+
+	if _, err := Predict(a, b, 1.5); err == nil {
+		t.Errorf("Expected an error for a draw rate above 1")
+	}
+}
+
+func TestMatchQuality(t *testing.T) {
+	sys := NewDefaultSystem()
+	a := NewRating(1500, 0, DefaultVol, sys)
+	if q := MatchQuality(a, a); q != 1 {
+		t.Errorf("Quality of an even game between certain ratings was %v", q)
+	}
+
+	even := MatchQuality(NewRating(1500, 50, DefaultVol, sys), NewRating(1500, 50, DefaultVol, sys))
+	uneven := MatchQuality(NewRating(1700, 50, DefaultVol, sys), NewRating(1500, 50, DefaultVol, sys))
+	uncertain := MatchQuality(NewRating(1500, 350, DefaultVol, sys), NewRating(1500, 50, DefaultVol, sys))
+	if even >= 1 || uneven >= even || uncertain >= even {
+		t.Errorf("Qualities even %v, uneven %v, uncertain %v", even, uneven, uncertain)
+	}
+	if q := MatchQuality(NewRating(1500, 50, DefaultVol, sys), NewRating(1700, 50, DefaultVol, sys)); !floatsMostlyEqual(q, uneven, 1e-12) {
+		t.Errorf("Quality not symmetric: %v != %v", q, uneven)
+	}
+}
@@ -2,14 +2,18 @@ package goglicko
 
 import (
 	"fmt"
+	"time"
 )
 
 // Represents a player's rating and the confidence in a player's rating.
 type Rating struct {
-	rating     float64 // Player's rating. Usually starts off at 1500.
-	deviation  float64 // Confidence/uncertainty in a player's rating
-	volatility float64 // Measures erratic performances
-	system     *System // the values from which the rating was created
+	rating     float64  // Player's rating. Usually starts off at 1500.
+	deviation  float64  // Confidence/uncertainty in a player's rating
+	volatility float64  // Measures erratic performances
+	games      int      // Games rated by Update
+	periods    int      // Rating periods in which the player had games
+	system     *System  // the values from which the rating was created
+	history    *History // optional record of past values, see SetHistory
 }
 
 // Creates a default Rating using:
@@ -17,12 +21,12 @@ type Rating struct {
 // 	Deviation  = DefaultDev
 // 	Volatility = DefaultVol
 func NewDefaultRating() *Rating {
-	return &Rating{DefaultRat, DefaultDev, DefaultVol, NewDefaultSystem()}
+	return &Rating{DefaultRat, DefaultDev, DefaultVol, 0, 0, NewDefaultSystem(), nil}
 }
 
 // Creates a new custom Rating.
 func NewRating(r, rd, s float64, sys *System) *Rating {
-	return &Rating{r, rd, s, sys}
+	return &Rating{r, rd, s, 0, 0, sys, nil}
 }
 
 // Creates a new rating, converted from Glicko1 scaling to Glicko2 scaling.
@@ -48,9 +52,11 @@ func (r *Rating) String() string {
 		r.rating, r.deviation, r.volatility)
 }
 
-// Create a duplicate rating with the same values.
+// Create a duplicate rating with the same values. The copy does not record
+// into this rating's History.
 func (r *Rating) Copy() *Rating {
 	rCopy := *r
+	rCopy.history = nil
 
 	return &rCopy
 }
@@ -64,3 +70,48 @@ func (r *Rating) GetSystem() *System {
 func (r *Rating) GetValues() (float64, float64, float64) {
 	return r.rating, r.deviation, r.volatility
 }
+
+// Games returns the number of games rated by Update.
+func (r *Rating) Games() int {
+	return r.games
+}
+
+// Periods returns the number of updates in which the player had games, which
+// is the number of rating periods they played in.
+func (r *Rating) Periods() int {
+	return r.periods
+}
+
+// SetCounts sets the number of games and rating periods played, e.g. when
+// restoring a rating from storage.
+func (r *Rating) SetCounts(games, periods int) {
+	r.games = games
+	r.periods = periods
+}
+
+// Provisional returns whether the rating is still provisional by the
+// thresholds of its System, see System.SetProvisional.
+func (r *Rating) Provisional() bool {
+	if r.system == nil {
+		return false
+	}
+	maxDev, minGames := r.system.GetProvisional()
+	return (maxDev > 0 && r.deviation > maxDev) || r.games < minGames
+}
+
+// SetHistory makes UpdateAt record a Snapshot into h after every update. A nil
+// History turns recording off.
+func (r *Rating) SetHistory(h *History) {
+	r.history = h
+}
+
+// History returns the History this rating records into, or nil if it isn't
+// recording.
+func (r *Rating) History() *History {
+	return r.history
+}
+
+// Snapshot returns the current values of the rating, as of time t.
+func (r *Rating) Snapshot(t time.Time, games int) Snapshot {
+	return Snapshot{t, r.rating, r.deviation, r.volatility, games}
+}
@@ -16,3 +16,41 @@ func TestStringyf(t *testing.T) {
 		t.Errorf("Error. String form was %v", def.String())
 	}
 }
+
+func TestCountsAndProvisional(t *testing.T) {
+	sys := NewDefaultSystem()
+	sys.SetProvisional(200, 3)
+	r := sys.NewRating()
+	opp := NewRating(1500, 100, DefaultVol, sys)
+
+	if !r.Provisional() {
+		t.Errorf("A new rating should be provisional")
+	}
+	if err := r.Update([]*Rating{opp, opp}, []Result{Win, Draw}); err != nil {
+		t.Fatalf("Error while updating: %v", err)
+	}
+	if err := r.Update(nil, nil); err != nil {
+		t.Fatalf("Error while updating: %v", err)
+	}
+	if r.Games() != 2 || r.Periods() != 1 {
+		t.Errorf("Counted %v games in %v periods, expected 2 in 1", r.Games(), r.Periods())
+	}
+	if err := r.Update([]*Rating{opp}, []Result{Loss}); err != nil {
+		t.Fatalf("Error while updating: %v", err)
+	}
+	if r.Games() != 3 || r.Periods() != 2 || r.Copy().Games() != 3 {
+		t.Errorf("Counted %v games in %v periods, expected 3 in 2", r.Games(), r.Periods())
+	}
+
+	_, dev, _ := r.GetValues()
+	if provisional := dev > 200; r.Provisional() != provisional {
+		t.Errorf("Provisional %v with deviation %v", r.Provisional(), dev)
+	}
+	r.SetCounts(1, 1)
+	if !r.Provisional() {
+		t.Errorf("A rating with 1 game should be provisional")
+	}
+	if NewRating(1500, 350, DefaultVol, NewDefaultSystem()).Provisional() {
+		t.Errorf("Ratings should never be provisional without thresholds")
+	}
+}
@@ -0,0 +1,266 @@
+package goglicko
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// Replay recomputes every rating from scratch from a game log, period by
+// period. Given the same games and System the outcome is always the same, so
+// it can be used to rebuild a pool after changing parameters or fixing data.
+//
+// The ratings at the end of every period are kept, so that games can be
+// inserted or voided after the fact by recomputing only the players affected.
+type Replay struct {
+	System  *System
+	Periods []*Period
+	origin  time.Time
+	length  time.Duration
+	states  []map[string]*Rating // ratings at the end of each period
+}
+
+// NewReplay rates games in periods of the given length, the first starting at
+// origin. A zero origin starts the first period at the earliest game, or
+// without games, at the first game inserted.
+func NewReplay(games []*Game, sys *System, origin time.Time, length time.Duration) (*Replay, error) {
+	if origin.IsZero() {
+		for _, g := range games {
+			if origin.IsZero() || g.Played.Before(origin) {
+				origin = g.Played
+			}
+		}
+	}
+
+	periods, err := GroupPeriods(games, origin, length)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Replay{sys, periods, origin, length, make([]map[string]*Rating, len(periods))}
+	ratings := make(map[string]*Rating)
+	for i, p := range periods {
+		if err := RatePeriod(ratings, p, sys); err != nil {
+			return nil, err
+		}
+
+		r.states[i] = make(map[string]*Rating, len(ratings))
+		for player, rating := range ratings {
+			r.states[i][player] = rating.Copy()
+		}
+	}
+
+	return r, nil
+}
+
+// current returns the ratings at the end of the last period.
+func (r *Replay) current() map[string]*Rating {
+	if len(r.states) == 0 {
+		return map[string]*Rating{}
+	}
+	return r.states[len(r.states)-1]
+}
+
+// Ratings returns a copy of the ratings of every player at the end of the last
+// period.
+func (r *Replay) Ratings() map[string]*Rating {
+	ratings := make(map[string]*Rating, len(r.current()))
+	for player, rating := range r.current() {
+		ratings[player] = rating.Copy()
+	}
+	return ratings
+}
+
+// RatingDiff is a player whose replayed rating differs from their current one.
+type RatingDiff struct {
+	Player string
+	Old    *Rating // nil if the player has no current rating
+	New    *Rating // nil if the player has no games in the replay
+}
+
+// Change returns how many rating points the replay moved the player, or 0 if
+// they are missing from either side.
+func (d RatingDiff) Change() float64 {
+	if d.Old == nil || d.New == nil {
+		return 0
+	}
+	return d.New.rating - d.Old.rating
+}
+
+// Diff compares the replayed ratings against current ones, such as those held
+// by a Store, and returns every player whose rating, deviation or volatility
+// differs by more than epsilon, ordered by player.
+func (r *Replay) Diff(current map[string]*Rating, epsilon float64) []RatingDiff {
+	var diffs []RatingDiff
+	for player, old := range current {
+		rating, ok := r.current()[player]
+		if !ok {
+			diffs = append(diffs, RatingDiff{player, old, nil})
+			continue
+		}
+		if math.Abs(old.rating-rating.rating) > epsilon ||
+			math.Abs(old.deviation-rating.deviation) > epsilon ||
+			math.Abs(old.volatility-rating.volatility) > epsilon {
+			diffs = append(diffs, RatingDiff{player, old, rating.Copy()})
+		}
+	}
+	for player, rating := range r.current() {
+		if _, ok := current[player]; !ok {
+			diffs = append(diffs, RatingDiff{player, nil, rating.Copy()})
+		}
+	}
+
+	sort.Slice(diffs, func(i, j int) bool {
+		return diffs[i].Player < diffs[j].Player
+	})
+	return diffs
+}
+
+// Insert rates a game that was reported late, in the period it was played,
+// assigning it an ID if it has none. Only its players, and those affected
+// through their later games, are recomputed. The players whose ratings were
+// recomputed are returned in order.
+func (r *Replay) Insert(g *Game) ([]string, error) {
+	if err := g.Validate(); err != nil {
+		return nil, err
+	}
+	if r.origin.IsZero() {
+		r.origin = g.Played
+	}
+	if g.Played.Before(r.origin) {
+		return nil, fmt.Errorf("Game %v was played at %v, before the first period starts at %v",
+			g.ID, g.Played, r.origin)
+	}
+
+	var maxID int64
+	for _, p := range r.Periods {
+		for _, pg := range p.Games {
+			if g.ID != 0 && pg.ID == g.ID {
+				return nil, fmt.Errorf("Game %v is already in the replay", g.ID)
+			}
+			if pg.ID > maxID {
+				maxID = pg.ID
+			}
+		}
+	}
+	if g.ID == 0 {
+		g.ID = maxID + 1
+	}
+
+	index := int(g.Played.Sub(r.origin) / r.length)
+	from := index
+	if len(r.Periods) < from {
+		from = len(r.Periods)
+	}
+	for len(r.Periods) <= index {
+		start := r.origin.Add(time.Duration(len(r.Periods)) * r.length)
+		r.Periods = append(r.Periods, &Period{
+			ID:    int64(len(r.Periods) + 1),
+			Start: start,
+			End:   start.Add(r.length),
+		})
+		r.states = append(r.states, nil)
+	}
+
+	p := r.Periods[index]
+	p.Games = append(p.Games, g)
+	sort.SliceStable(p.Games, func(i, j int) bool {
+		return p.Games[i].Played.Before(p.Games[j].Played)
+	})
+
+	return r.recompute(from, g.Player1, g.Player2)
+}
+
+// Void removes a game, such as one annulled after a cheating investigation,
+// and recomputes the players affected by it. The players whose ratings were
+// recomputed are returned in order.
+func (r *Replay) Void(id int64) ([]string, error) {
+	for index, p := range r.Periods {
+		for i, g := range p.Games {
+			if g.ID != id {
+				continue
+			}
+
+			p.Games = append(p.Games[:i:i], p.Games[i+1:]...)
+			return r.recompute(index, g.Player1, g.Player2)
+		}
+	}
+
+	return nil, fmt.Errorf("Game %v is not in the replay", id)
+}
+
+// recompute re-rates players from period index onwards. Anyone who plays an
+// affected player is affected from then on, since their update depends on the
+// affected player's rating. Everybody else keeps their stored ratings.
+func (r *Replay) recompute(index int, players ...string) ([]string, error) {
+	affected := make(map[string]bool)
+	for _, player := range players {
+		affected[player] = true
+	}
+
+	for k := index; k < len(r.Periods); k++ {
+		p := r.Periods[k]
+		var newly []string
+		for _, g := range p.Games {
+			if affected[g.Player1] != affected[g.Player2] {
+				newly = append(newly, g.Player1, g.Player2)
+			}
+		}
+		for _, player := range newly {
+			affected[player] = true
+		}
+
+		var before map[string]*Rating
+		if k > 0 {
+			before = r.states[k-1]
+		}
+
+		// Every game of an affected player is rated, against opponents at
+		// their ratings from before the period, but only the affected players'
+		// new ratings are kept.
+		sub := &Period{ID: p.ID, Start: p.Start, End: p.End}
+		ratings := make(map[string]*Rating)
+		for player := range affected {
+			if rating, ok := before[player]; ok {
+				ratings[player] = rating.Copy()
+			}
+		}
+		for _, g := range p.Games {
+			if !affected[g.Player1] && !affected[g.Player2] {
+				continue
+			}
+			sub.Games = append(sub.Games, g)
+			for _, player := range []string{g.Player1, g.Player2} {
+				if rating, ok := before[player]; ok && ratings[player] == nil {
+					ratings[player] = rating.Copy()
+				}
+			}
+		}
+		if err := RatePeriod(ratings, sub, r.System); err != nil {
+			return nil, err
+		}
+
+		if r.states[k] == nil {
+			r.states[k] = make(map[string]*Rating)
+			for player, rating := range before {
+				r.states[k][player] = rating.Copy()
+				r.states[k][player].updateIdle(nil)
+			}
+		}
+		for player := range affected {
+			if rating, ok := ratings[player]; ok {
+				r.states[k][player] = rating
+			} else {
+				delete(r.states[k], player)
+			}
+		}
+	}
+
+	recomputed := make([]string, 0, len(affected))
+	for player := range affected {
+		recomputed = append(recomputed, player)
+	}
+	sort.Strings(recomputed)
+	return recomputed, nil
+}
@@ -0,0 +1,253 @@
+package goglicko
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestReplay(t *testing.T) {
+	sys := NewDefaultSystem()
+	day := 24 * time.Hour
+	games := []*Game{
+		{ID: 1, Player1: "a", Player2: "b", Result: Win, Played: periodOrigin, Weight: 1},
+		{ID: 2, Player1: "b", Player2: "c", Result: Draw, Played: periodOrigin.Add(day), Weight: 1},
+		{ID: 3, Player1: "c", Player2: "a", Result: Loss, Played: periodOrigin.Add(2 * day), Weight: 1},
+	}
+
+	r1, err := NewReplay(games, sys, time.Time{}, day)
+	if err != nil {
+		t.Fatalf("Error while replaying: %v", err)
+	}
+	if len(r1.Periods) != 3 {
+		t.Errorf("len(Periods) %v != 3", len(r1.Periods))
+	}
+
+	r2, err := NewReplay(games, sys, time.Time{}, day)
+	if err != nil {
+		t.Fatalf("Error while replaying: %v", err)
+	}
+	if diffs := r2.Diff(r1.Ratings(), 0); len(diffs) != 0 {
+		t.Errorf("Replays of the same log differ: %v", diffs)
+	}
+
+	current := r1.Ratings()
+	current["a"] = NewRating(1600, 100, DefaultVol, sys)
+	current["gone"] = NewRating(1500, 100, DefaultVol, sys)
+	delete(current, "c")
+
+	diffs := r1.Diff(current, 0.001)
+	expPlayers := []string{"a", "c", "gone"}
+	if len(diffs) != len(expPlayers) {
+		t.Fatalf("diffs %v, expected players %v", diffs, expPlayers)
+	}
+	for i, d := range diffs {
+		if d.Player != expPlayers[i] {
+			t.Errorf("diffs[%v].Player %v != %v", i, d.Player, expPlayers[i])
+		}
+	}
+	if diffs[1].Old != nil || diffs[2].New != nil {
+		t.Errorf("Added and removed players not reported: %v", diffs)
+	}
+	if exp := r1.Ratings()["a"].rating - 1600; diffs[0].Change() != exp {
+		t.Errorf("Change %v != %v", diffs[0].Change(), exp)
+	}
+}
+
+func TestReplayInsertVoid(t *testing.T) {
+	sys := NewDefaultSystem()
+	day := 24 * time.Hour
+	games := []*Game{
+		{ID: 1, Player1: "a", Player2: "b", Result: Win, Played: periodOrigin, Weight: 1},
+		{ID: 2, Player1: "c", Player2: "d", Result: Draw, Played: periodOrigin, Weight: 1},
+		{ID: 3, Player1: "b", Player2: "c", Result: Win, Played: periodOrigin.Add(day), Weight: 1},
+		{ID: 4, Player1: "e", Player2: "f", Result: Loss, Played: periodOrigin.Add(2 * day), Weight: 1},
+		{ID: 5, Player1: "c", Player2: "a", Result: Loss, Played: periodOrigin.Add(3 * day), Weight: 1},
+	}
+	late := &Game{Player1: "d", Player2: "a", Result: Win, Played: periodOrigin.Add(day + time.Hour), Weight: 1}
+
+	r, err := NewReplay(games, sys, periodOrigin, day)
+	if err != nil {
+		t.Fatalf("Error while replaying: %v", err)
+	}
+
+	t.Run("TestInsert", func(t *testing.T) {
+		recomputed, err := r.Insert(late)
+		if err != nil {
+			t.Fatalf("Error while inserting: %v", err)
+		}
+		if late.ID != 6 {
+			t.Errorf("late.ID %v != 6", late.ID)
+		}
+
+		// c is affected through their later game with a, but b and the
+		// e-f pair never meet anyone affected by the late game.
+		exp := []string{"a", "c", "d"}
+		if len(recomputed) != len(exp) {
+			t.Fatalf("recomputed %v != %v", recomputed, exp)
+		}
+		for i := range exp {
+			if recomputed[i] != exp[i] {
+				t.Errorf("recomputed %v != %v", recomputed, exp)
+			}
+		}
+
+		full, err := NewReplay(append(games, late), sys, periodOrigin, day)
+		if err != nil {
+			t.Fatalf("Error while replaying: %v", err)
+		}
+		if diffs := full.Diff(r.Ratings(), 1e-9); len(diffs) != 0 {
+			t.Errorf("Incremental insert differs from a full replay: %v", diffs)
+		}
+	})
+
+	t.Run("TestVoid", func(t *testing.T) {
+		if _, err := r.Void(4); err != nil {
+			t.Fatalf("Error while voiding: %v", err)
+		}
+		if _, err := r.Void(4); err == nil {
+			t.Errorf("Expected an error voiding a game twice")
+		}
+
+		// Voiding e and f's only game drops them from the pool entirely.
+		full, err := NewReplay([]*Game{games[0], games[1], games[2], late, games[4]},
+			sys, periodOrigin, day)
+		if err != nil {
+			t.Fatalf("Error while replaying: %v", err)
+		}
+		if diffs := full.Diff(r.Ratings(), 1e-9); len(diffs) != 0 {
+			t.Errorf("Incremental void differs from a full replay: %v", diffs)
+		}
+	})
+
+	t.Run("TestInsertDuplicateID", func(t *testing.T) {
+		g := &Game{ID: 3, Player1: "a", Player2: "e", Result: Draw, Played: periodOrigin.Add(day), Weight: 1}
+		if _, err := r.Insert(g); err == nil {
+			t.Errorf("Expected an error inserting a game with an existing ID")
+		}
+	})
+
+	t.Run("TestInsertAfterLastPeriod", func(t *testing.T) {
+		g := &Game{Player1: "a", Player2: "e", Result: Draw, Played: periodOrigin.Add(6 * day), Weight: 1}
+		if _, err := r.Insert(g); err != nil {
+			t.Fatalf("Error while inserting: %v", err)
+		}
+		if len(r.Periods) != 7 {
+			t.Errorf("len(Periods) %v != 7", len(r.Periods))
+		}
+
+		full, err := NewReplay([]*Game{games[0], games[1], games[2], late, games[4], g},
+			sys, periodOrigin, day)
+		if err != nil {
+			t.Fatalf("Error while replaying: %v", err)
+		}
+		if diffs := full.Diff(r.Ratings(), 1e-9); len(diffs) != 0 {
+			t.Errorf("Incremental insert differs from a full replay: %v", diffs)
+		}
+	})
+}
+
+func TestReplayInsertWithoutOrigin(t *testing.T) {
+	r, err := NewReplay(nil, NewDefaultSystem(), time.Time{}, 24*time.Hour)
+	if err != nil {
+		t.Fatalf("Error while replaying: %v", err)
+	}
+
+	g := &Game{Player1: "a", Player2: "b", Result: Win, Played: periodOrigin.Add(time.Hour), Weight: 1}
+	if _, err := r.Insert(g); err != nil {
+		t.Fatalf("Error while inserting: %v", err)
+	}
+	if len(r.Periods) != 1 || !r.Periods[0].Start.Equal(g.Played) {
+		t.Errorf("Periods %v, expected one starting at the inserted game", r.Periods)
+	}
+	if g.ID != 1 {
+		t.Errorf("g.ID %v != 1", g.ID)
+	}
+}
+
+func TestReplayInsertOpponentOrder(t *testing.T) {
+	sys := NewDefaultSystem()
+	day := 24 * time.Hour
+	at := func(d, h int) time.Time { return periodOrigin.Add(time.Duration(d)*day + time.Duration(h)*time.Hour) }
+	games := []*Game{
+		{ID: 1, Player1: "d", Player2: "x", Result: Win, Played: at(0, 1), Weight: 1},
+		{ID: 2, Player1: "d", Player2: "x", Result: Win, Played: at(0, 2), Weight: 1},
+		// c only becomes affected at their second game, after playing d.
+		{ID: 3, Player1: "c", Player2: "d", Result: Win, Played: at(1, 1), Weight: 1},
+		{ID: 4, Player1: "a", Player2: "c", Result: Loss, Played: at(1, 2), Weight: 1},
+		{ID: 5, Player1: "a", Player2: "b", Result: Win, Played: at(1, 3), Weight: 1},
+	}
+	late := &Game{ID: 6, Player1: "a", Player2: "b", Result: Win, Played: at(0, 3), Weight: 1}
+
+	r, err := NewReplay(games, sys, periodOrigin, day)
+	if err != nil {
+		t.Fatalf("Error while replaying: %v", err)
+	}
+	if _, err := r.Insert(late); err != nil {
+		t.Fatalf("Error while inserting: %v", err)
+	}
+	full, err := NewReplay(append(games, late), sys, periodOrigin, day)
+	if err != nil {
+		t.Fatalf("Error while replaying: %v", err)
+	}
+	if diffs := full.Diff(r.Ratings(), 1e-9); len(diffs) != 0 {
+		t.Errorf("Incremental insert differs from a full replay: %v", diffs)
+	}
+}
+
+func TestReplayMatchesFullReplay(t *testing.T) {
+	sys := NewDefaultSystem()
+	day := 24 * time.Hour
+	rnd := rand.New(rand.NewSource(1))
+	players := []string{"a", "b", "c", "d", "e", "f", "g", "h"}
+	days := 6
+
+	// Games are played at random times, so their order within each period
+	// is shuffled. The last game keeps the last period from emptying.
+	game := func(id int64, d int) *Game {
+		i, j := rnd.Intn(len(players)), rnd.Intn(len(players)-1)
+		if j >= i {
+			j++
+		}
+		return &Game{ID: id, Player1: players[i], Player2: players[j], Result: Result(rnd.Intn(3)) / 2,
+			Played: periodOrigin.Add(time.Duration(d)*day + time.Duration(rnd.Int63n(int64(day)))), Weight: 1}
+	}
+	var games []*Game
+	for len(games) < 40 {
+		games = append(games, game(int64(len(games)+1), rnd.Intn(days)))
+	}
+	games = append(games, &Game{ID: 1000, Player1: "y", Player2: "z", Result: Draw,
+		Played: periodOrigin.Add(time.Duration(days) * day), Weight: 1})
+
+	r, err := NewReplay(games, sys, periodOrigin, day)
+	if err != nil {
+		t.Fatalf("Error while replaying: %v", err)
+	}
+	compare := func(step string) {
+		var all []*Game
+		for _, p := range r.Periods {
+			all = append(all, p.Games...)
+		}
+		full, err := NewReplay(all, sys, periodOrigin, day)
+		if err != nil {
+			t.Fatalf("Error while replaying: %v", err)
+		}
+		if diffs := full.Diff(r.Ratings(), 1e-9); len(diffs) != 0 {
+			t.Fatalf("After %v, the replay differs from a full replay: %v", step, diffs)
+		}
+	}
+
+	for i := 0; i < 20; i++ {
+		g := game(0, rnd.Intn(days))
+		if _, err := r.Insert(g); err != nil {
+			t.Fatalf("Error while inserting: %v", err)
+		}
+		compare(fmt.Sprintf("inserting game %v", g.ID))
+
+		id := int64(rnd.Intn(len(games)-1) + 1)
+		if _, err := r.Void(id); err == nil {
+			compare(fmt.Sprintf("voiding game %v", id))
+		}
+	}
+}
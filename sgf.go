@@ -0,0 +1,292 @@
+package goglicko
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SGFGame is the root node of a Go game record in SGF.
+type SGFGame struct {
+	Black    string    // PB
+	White    string    // PW
+	Date     time.Time // First date of DT; zero if missing
+	Handicap int       // HA
+	Komi     float64   // KM
+	RE       string    // The raw result, e.g. B+R, W+3.5, 0 or Void
+
+	// Winner is "B" or "W", or empty for a draw or a game without a result.
+	Winner string
+	// Reason the game was won: "R" for resignation, "T" for time, "F" for
+	// forfeit, the margin in points as written, or empty if not recorded.
+	Reason string
+
+	Properties map[string][]string // Every property of the root node
+}
+
+// Rated returns whether the game has an outcome that can be rated. Void,
+// unknown and forfeited games can't be.
+func (sg *SGFGame) Rated() bool {
+	if sg.Reason == "F" {
+		return false
+	}
+	return sg.Winner != "" || sg.isDraw()
+}
+
+func (sg *SGFGame) isDraw() bool {
+	switch strings.ToLower(sg.RE) {
+	case "0", "draw", "jigo":
+		return true
+	}
+	return false
+}
+
+// GoHandicap turns handicap stones and komi into a rating advantage for Black.
+type GoHandicap struct {
+	StoneValue float64 // Rating points one stone of handicap is worth
+	EvenKomi   float64 // Komi of an even game under the rules played, e.g. 6.5
+}
+
+// Advantage returns the rating points Black's handicap is worth. A handicap
+// game gives White the first move, so n stones are worth n-1 stones over a no
+// komi game, and each point of komi short of EvenKomi is worth a further half
+// stone per EvenKomi. A handicap of 1 is a no komi game, worth its komi alone.
+func (h GoHandicap) Advantage(handicap int, komi float64) float64 {
+	stones := math.Max(float64(handicap-1), 0)
+	if h.EvenKomi != 0 {
+		stones += (h.EvenKomi - komi) / (2 * h.EvenKomi)
+	}
+	return stones * h.StoneValue
+}
+
+// Game converts the record into a Game rated from Black's point of view, with
+// the handicap applied as an Advantage for Black.
+func (sg *SGFGame) Game(h GoHandicap) (*Game, error) {
+	if !sg.Rated() {
+		return nil, fmt.Errorf("Can't rate a game with result %q", sg.RE)
+	}
+
+	g := &Game{
+		Player1:   sg.Black,
+		Player2:   sg.White,
+		Played:    sg.Date,
+		Weight:    1,
+		Advantage: h.Advantage(sg.Handicap, sg.Komi),
+	}
+	switch sg.Winner {
+	case "B":
+		g.Result = Win
+	case "W":
+		g.Result = Loss
+	default:
+		g.Result = Draw
+	}
+	return g, g.Validate()
+}
+
+// ReadSGF reads the root node of every game in an SGF collection.
+func ReadSGF(r io.Reader) ([]*SGFGame, error) {
+	p := &sgfParser{r: bufio.NewReader(r)}
+	var games []*SGFGame
+	for {
+		props, err := p.nextRoot()
+		if err == io.EOF {
+			return games, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		sg, err := newSGFGame(props)
+		if err != nil {
+			return nil, err
+		}
+		games = append(games, sg)
+	}
+}
+
+func newSGFGame(props map[string][]string) (*SGFGame, error) {
+	first := func(id string) string {
+		if v := props[id]; len(v) > 0 {
+			return strings.TrimSpace(v[0])
+		}
+		return ""
+	}
+
+	sg := &SGFGame{
+		Black:      first("PB"),
+		White:      first("PW"),
+		RE:         first("RE"),
+		Properties: props,
+	}
+
+	var err error
+	if ha := first("HA"); ha != "" {
+		if sg.Handicap, err = strconv.Atoi(ha); err != nil {
+			return nil, fmt.Errorf("Invalid handicap HA[%v]", ha)
+		}
+	}
+	if km := first("KM"); km != "" {
+		if sg.Komi, err = strconv.ParseFloat(km, 64); err != nil {
+			return nil, fmt.Errorf("Invalid komi KM[%v]", km)
+		}
+	}
+	if dt := first("DT"); dt != "" {
+		sg.Date = parseSGFDate(dt)
+	}
+
+	if plus := strings.Index(sg.RE, "+"); plus == 1 {
+		switch strings.ToUpper(sg.RE[:1]) {
+		case "B", "W":
+			sg.Winner = strings.ToUpper(sg.RE[:1])
+		}
+
+		reason := sg.RE[plus+1:]
+		switch strings.ToLower(reason) {
+		case "r", "resign":
+			sg.Reason = "R"
+		case "t", "time":
+			sg.Reason = "T"
+		case "f", "forfeit":
+			sg.Reason = "F"
+		default:
+			sg.Reason = reason
+		}
+	}
+
+	return sg, nil
+}
+
+// parseSGFDate parses the first date of a DT property, such as 2017-03-01 or
+// 2017-03-01,02. Missing months and days are taken as the 1st.
+func parseSGFDate(s string) time.Time {
+	s = strings.SplitN(s, ",", 2)[0]
+	for _, layout := range []string{"2006-01-02", "2006-01", "2006"} {
+		if t, err := time.Parse(layout, strings.TrimSpace(s)); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// sgfParser reads game trees out of an SGF collection.
+type sgfParser struct {
+	r *bufio.Reader
+}
+
+// nextRoot returns the properties of the root node of the next game tree and
+// skips the rest of the tree.
+func (p *sgfParser) nextRoot() (map[string][]string, error) {
+	if err := p.skipTo('('); err != nil {
+		return nil, err
+	}
+	if err := p.skipTo(';'); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+
+	props := make(map[string][]string)
+	id := ""
+	hasValue := false // whether id has had a value, so a new letter starts a new id
+	for {
+		c, err := p.r.ReadByte()
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+
+		switch {
+		case c >= 'A' && c <= 'Z':
+			if hasValue {
+				id, hasValue = "", false
+			}
+			id += string(c)
+		case c >= 'a' && c <= 'z':
+			// Lower case letters in identifiers are an FF[3] leftover.
+		case c == '[':
+			value, err := p.readValue()
+			if err != nil {
+				return nil, err
+			}
+			props[id] = append(props[id], value)
+			hasValue = true
+		case c == ';' || c == '(' || c == ')':
+			depth := 1
+			if c == '(' {
+				depth++
+			} else if c == ')' {
+				depth--
+			}
+			return props, p.skipTree(depth)
+		}
+	}
+}
+
+// readValue reads a property value up to its closing bracket, unescaping it.
+func (p *sgfParser) readValue() (string, error) {
+	var b strings.Builder
+	for {
+		c, err := p.r.ReadByte()
+		if err != nil {
+			return "", unexpectedEOF(err)
+		}
+
+		switch c {
+		case '\\':
+			c, err = p.r.ReadByte()
+			if err != nil {
+				return "", unexpectedEOF(err)
+			}
+			if c == '\n' || c == '\r' {
+				continue // soft line break
+			}
+		case ']':
+			return b.String(), nil
+		}
+		b.WriteByte(c)
+	}
+}
+
+// skipTree skips to the end of a game tree, depth levels of parentheses deep.
+func (p *sgfParser) skipTree(depth int) error {
+	for depth > 0 {
+		c, err := p.r.ReadByte()
+		if err != nil {
+			return unexpectedEOF(err)
+		}
+
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case '[':
+			if _, err := p.readValue(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// skipTo reads up to and including the byte c.
+func (p *sgfParser) skipTo(c byte) error {
+	for {
+		b, err := p.r.ReadByte()
+		if err != nil {
+			return err
+		}
+		if b == c {
+			return nil
+		}
+	}
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return fmt.Errorf("Unexpected end of SGF: %v", io.ErrUnexpectedEOF)
+	}
+	return err
+}
@@ -0,0 +1,100 @@
+package goglicko
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const testSGF = `(;GM[1]FF[4]SZ[19]PB[Black \] Player]PW[White]DT[2017-03-01,02]
+RE[W+R]KM[6.5]
+;B[pd];W[dp](;B[pp])(;B[dd]))
+(;GM[1]FF[4]PB[Alice]PW[Bob]HA[3]KM[0.5]RE[B+12.5]DT[2017-03-05]
+AB[dd][pd][dp];W[pp])
+(;GM[1]FF[4]PB[Alice]PW[Carol]RE[B+T]DT[2017-03])
+(;GM[1]FF[4]PB[Bob]PW[Carol]RE[Void])`
+
+func TestReadSGF(t *testing.T) {
+	games, err := ReadSGF(strings.NewReader(testSGF))
+	if err != nil {
+		t.Fatalf("Error while reading: %v", err)
+	}
+	if len(games) != 4 {
+		t.Fatalf("Read %v games, expected 4", len(games))
+	}
+
+	g := games[0]
+	if g.Black != "Black ] Player" || g.White != "White" || g.Komi != 6.5 ||
+		g.Winner != "W" || g.Reason != "R" {
+		t.Errorf("First game read as %+v", g)
+	}
+	if !g.Date.Equal(time.Date(2017, time.March, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Date read as %v", g.Date)
+	}
+
+	g = games[1]
+	if g.Handicap != 3 || g.Komi != 0.5 || g.Winner != "B" || g.Reason != "12.5" ||
+		len(g.Properties["AB"]) != 3 {
+		t.Errorf("Handicap game read as %+v", g)
+	}
+
+	if g := games[2]; g.Reason != "T" || !g.Rated() {
+		t.Errorf("Time loss read as %+v", g)
+	}
+	if games[3].Rated() {
+		t.Errorf("Void game is rated")
+	}
+}
+
+func TestGoHandicap(t *testing.T) {
+	h := GoHandicap{StoneValue: 100, EvenKomi: 6.5}
+	tests := []struct {
+		handicap int
+		komi     float64
+		exp      float64
+	}{
+		{0, 6.5, 0},
+		{0, 0.5, 100 * 6 / 13.0},
+		{1, 0.5, 100 * 6 / 13.0},
+		{1, 6.5, 0},
+		{2, 0.5, 100 + 100*6/13.0},
+		{3, 0.5, 200 + 100*6/13.0},
+	}
+	for _, test := range tests {
+		adv := h.Advantage(test.handicap, test.komi)
+		if !floatsMostlyEqual(adv, test.exp, 0.0001) {
+			t.Errorf("Advantage(%v, %v) %v != %v", test.handicap, test.komi, adv, test.exp)
+		}
+	}
+}
+
+func TestSGFGameAdvantage(t *testing.T) {
+	games, err := ReadSGF(strings.NewReader(testSGF))
+	if err != nil {
+		t.Fatalf("Error while reading: %v", err)
+	}
+
+	g, err := games[1].Game(GoHandicap{StoneValue: 100, EvenKomi: 6.5})
+	if err != nil {
+		t.Fatalf("Error while converting: %v", err)
+	}
+	if g.Player1 != "Alice" || g.Result != Win || g.Advantage <= 200 {
+		t.Errorf("Handicap game converted to %+v", g)
+	}
+
+	// Winning a handicap game gains less than winning an even one.
+	sys := NewDefaultSystem()
+	even, handicap := map[string]*Rating{}, map[string]*Rating{}
+	evenGame := *g
+	evenGame.Advantage = 0
+	if err := RatePeriod(even, &Period{Games: []*Game{&evenGame}}, sys); err != nil {
+		t.Fatalf("Error rating the even game: %v", err)
+	}
+	if err := RatePeriod(handicap, &Period{Games: []*Game{g}}, sys); err != nil {
+		t.Fatalf("Error rating the handicap game: %v", err)
+	}
+	if handicap["Alice"].rating >= even["Alice"].rating {
+		t.Errorf("Handicap win %v gained as much as an even win %v",
+			handicap["Alice"], even["Alice"])
+	}
+}
@@ -0,0 +1,302 @@
+// Package sim simulates a league of players with hidden true skills, to see
+// how well a rating System recovers them.
+//
+// Skills are on the rating scale: a player 400 points stronger than another
+// is ten times as likely to win under EloOutcome. Each period, some players
+// leave, new ones arrive, every skill drifts a little, and the remaining
+// players play games that are then rated like any real period.
+package sim
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"time"
+
+	"github.com/clavoie/goglicko"
+)
+
+// Player is a simulated player.
+type Player struct {
+	Name   string
+	Skill  float64 // True skill at the end of the simulation, or when they left
+	Joined int     // Period they joined, counted from 1; 0 for the initial population
+	Left   int     // Period they left, or 0 if still active
+	Games  int
+
+	// Games played by the end of the first period in which the rating was
+	// within Config.Tolerance of the skill, or -1 if that never happened.
+	ConvergedAfter int
+}
+
+// Pairing picks the two players of the next game among the active players.
+type Pairing func(rnd *rand.Rand, active []*Player, ratings map[string]*goglicko.Rating) (*Player, *Player)
+
+// Outcome decides a game between players of the given skills, from the first
+// player's point of view.
+type Outcome func(rnd *rand.Rand, skill1, skill2 float64) goglicko.Result
+
+// RandomPairing pairs two different active players chosen uniformly.
+func RandomPairing(rnd *rand.Rand, active []*Player, ratings map[string]*goglicko.Rating) (*Player, *Player) {
+	i, j := rnd.Intn(len(active)), rnd.Intn(len(active)-1)
+	if j >= i {
+		j++
+	}
+	return active[i], active[j]
+}
+
+// ClosestPairing picks a random player and pairs them with the closest rated
+// of the given number of random candidates, like a matchmaker with a limited
+// pool would.
+func ClosestPairing(candidates int) Pairing {
+	return func(rnd *rand.Rand, active []*Player, ratings map[string]*goglicko.Rating) (*Player, *Player) {
+		p1, best := RandomPairing(rnd, active, ratings)
+		rating1, _, _ := ratings[p1.Name].GetValues()
+		bestRating, _, _ := ratings[best.Name].GetValues()
+		for i := 1; i < candidates; i++ {
+			_, p2 := RandomPairing(rnd, active, ratings)
+			if p2 == p1 {
+				continue
+			}
+			if rating2, _, _ := ratings[p2.Name].GetValues(); math.Abs(rating2-rating1) < math.Abs(bestRating-rating1) {
+				best, bestRating = p2, rating2
+			}
+		}
+		return p1, best
+	}
+}
+
+// EloOutcome decides games by the logistic curve of the rating scale.
+// drawRate is the chance of a draw between equal players; draws get less
+// likely the larger the skill gap.
+func EloOutcome(drawRate float64) Outcome {
+	return func(rnd *rand.Rand, skill1, skill2 float64) goglicko.Result {
+		e := 1 / (1 + math.Pow(10, (skill2-skill1)/400))
+		draw := drawRate * (1 - math.Abs(2*e-1))
+		switch x := rnd.Float64(); {
+		case x < e-draw/2:
+			return goglicko.Win
+		case x < e+draw/2:
+			return goglicko.Draw
+		}
+		return goglicko.Loss
+	}
+}
+
+// GaussianOutcome decides games by comparing performances drawn around each
+// skill with the given spread. Performances within drawMargin of each other
+// are a draw.
+func GaussianOutcome(spread, drawMargin float64) Outcome {
+	return func(rnd *rand.Rand, skill1, skill2 float64) goglicko.Result {
+		diff := (skill1 + rnd.NormFloat64()*spread) - (skill2 + rnd.NormFloat64()*spread)
+		switch {
+		case math.Abs(diff) <= drawMargin:
+			return goglicko.Draw
+		case diff > 0:
+			return goglicko.Win
+		}
+		return goglicko.Loss
+	}
+}
+
+// Config describes a simulated league.
+type Config struct {
+	Seed int64
+
+	Players   int     // Initial population
+	SkillMean float64 // Skills of new players are normally distributed
+	SkillSD   float64
+	Drift     float64 // Standard deviation of each skill's change per period
+	Churn     float64 // Chance an active player leaves in a period
+	Arrivals  float64 // Mean number of new players per period
+
+	Periods        int
+	PeriodLength   time.Duration
+	Start          time.Time
+	GamesPerPlayer float64 // Mean games an active player plays per period
+
+	Pairing Pairing // Defaults to RandomPairing
+	Outcome Outcome // Defaults to EloOutcome(0)
+
+	// How close a rating must get to the skill for the player to count as
+	// converged. Defaults to 100.
+	Tolerance float64
+}
+
+// DefaultConfig is a stable league of 100 players, each playing 10 games a
+// week for a year.
+var DefaultConfig = Config{
+	Seed:           1,
+	Players:        100,
+	SkillMean:      goglicko.DefaultRat,
+	SkillSD:        300,
+	Periods:        52,
+	PeriodLength:   7 * 24 * time.Hour,
+	Start:          time.Date(2017, time.January, 1, 0, 0, 0, 0, time.UTC),
+	GamesPerPlayer: 10,
+}
+
+// PeriodStats measure the ratings of the active players at the end of a
+// period against their skills.
+type PeriodStats struct {
+	Period        int
+	Players       int
+	Games         int
+	RMSE          float64 // Root mean squared difference of rating and skill
+	MeanAbsError  float64
+	MeanDeviation float64
+	Coverage      float64 // Share of players whose skill is within 2 deviations of their rating
+}
+
+// Report is the outcome of a simulation.
+type Report struct {
+	Periods []PeriodStats
+	Players []*Player // Everyone who ever played, in order of arrival
+	Games   []*goglicko.Game
+	Ratings map[string]*goglicko.Rating // Final ratings of the active players
+
+	// Mean ConvergedAfter of the players who converged, and how many did
+	// and didn't.
+	GamesToConverge float64
+	Converged       int
+	NotConverged    int
+}
+
+// Run simulates a league as configured, rating it with sys.
+func Run(c Config, sys *goglicko.System) (*Report, error) {
+	if sys == nil {
+		return nil, fmt.Errorf("Simulation needs a System")
+	}
+	if c.Periods <= 0 || c.PeriodLength <= 0 {
+		return nil, fmt.Errorf("Simulation needs a positive number and length of periods")
+	}
+	if c.Churn < 0 || c.Churn > 1 {
+		return nil, fmt.Errorf("Churn must be between 0 and 1, was %v", c.Churn)
+	}
+	if c.Players < 0 || c.Arrivals < 0 || c.GamesPerPlayer < 0 || c.Drift < 0 || c.SkillSD < 0 {
+		return nil, fmt.Errorf("Simulation parameters can't be negative")
+	}
+	if c.Pairing == nil {
+		c.Pairing = RandomPairing
+	}
+	if c.Outcome == nil {
+		c.Outcome = EloOutcome(0)
+	}
+	if c.Tolerance <= 0 {
+		c.Tolerance = 100
+	}
+
+	rnd := rand.New(rand.NewSource(c.Seed))
+	report := &Report{Ratings: make(map[string]*goglicko.Rating)}
+	var active []*Player
+	join := func(period int) {
+		p := &Player{
+			Name:           "p" + strconv.Itoa(len(report.Players)+1),
+			Skill:          c.SkillMean + rnd.NormFloat64()*c.SkillSD,
+			Joined:         period,
+			ConvergedAfter: -1,
+		}
+		report.Players = append(report.Players, p)
+		active = append(active, p)
+		report.Ratings[p.Name] = sys.NewRating()
+	}
+	for i := 0; i < c.Players; i++ {
+		join(0)
+	}
+
+	for index := 1; index <= c.Periods; index++ {
+		remaining := active[:0]
+		for _, p := range active {
+			if rnd.Float64() < c.Churn {
+				p.Left = index
+				delete(report.Ratings, p.Name)
+				continue
+			}
+			p.Skill += rnd.NormFloat64() * c.Drift
+			remaining = append(remaining, p)
+		}
+		active = remaining
+		for n := poisson(rnd, c.Arrivals); n > 0; n-- {
+			join(index)
+		}
+
+		start := c.Start.Add(time.Duration(index-1) * c.PeriodLength)
+		period := &goglicko.Period{ID: int64(index), Start: start, End: start.Add(c.PeriodLength)}
+		if len(active) >= 2 {
+			for n := poisson(rnd, float64(len(active))*c.GamesPerPlayer/2); n > 0; n-- {
+				p1, p2 := c.Pairing(rnd, active, report.Ratings)
+				g := &goglicko.Game{
+					ID:      int64(len(report.Games) + 1),
+					Player1: p1.Name,
+					Player2: p2.Name,
+					Result:  c.Outcome(rnd, p1.Skill, p2.Skill),
+					Played:  start,
+					Weight:  1,
+				}
+				p1.Games++
+				p2.Games++
+				period.Games = append(period.Games, g)
+				report.Games = append(report.Games, g)
+			}
+		}
+		if err := goglicko.RatePeriod(report.Ratings, period, sys); err != nil {
+			return nil, err
+		}
+
+		stats := PeriodStats{Period: index, Players: len(active), Games: len(period.Games)}
+		for _, p := range active {
+			rating, dev, _ := report.Ratings[p.Name].GetValues()
+			diff := math.Abs(rating - p.Skill)
+			stats.RMSE += diff * diff
+			stats.MeanAbsError += diff
+			stats.MeanDeviation += dev
+			if diff <= 2*dev {
+				stats.Coverage++
+			}
+			if p.ConvergedAfter < 0 && p.Games > 0 && diff <= c.Tolerance {
+				p.ConvergedAfter = p.Games
+			}
+		}
+		if n := float64(len(active)); n > 0 {
+			stats.RMSE = math.Sqrt(stats.RMSE / n)
+			stats.MeanAbsError /= n
+			stats.MeanDeviation /= n
+			stats.Coverage /= n
+		}
+		report.Periods = append(report.Periods, stats)
+	}
+
+	for _, p := range report.Players {
+		if p.ConvergedAfter < 0 {
+			report.NotConverged++
+			continue
+		}
+		report.Converged++
+		report.GamesToConverge += float64(p.ConvergedAfter)
+	}
+	if report.Converged > 0 {
+		report.GamesToConverge /= float64(report.Converged)
+	}
+	return report, nil
+}
+
+// poisson draws from a Poisson distribution with the given mean, by
+// inversion for small means and a normal approximation for large ones.
+func poisson(rnd *rand.Rand, mean float64) int {
+	if mean <= 0 {
+		return 0
+	}
+	if mean > 50 {
+		return int(math.Max(0, math.Round(mean+rnd.NormFloat64()*math.Sqrt(mean))))
+	}
+
+	n, p := 0, math.Exp(-mean)
+	cum, x := p, rnd.Float64()
+	for x > cum && p > 0 {
+		n++
+		p *= mean / float64(n)
+		cum += p
+	}
+	return n
+}
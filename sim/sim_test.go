@@ -0,0 +1,112 @@
+package sim
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/clavoie/goglicko"
+)
+
+func TestRunConverges(t *testing.T) {
+	report, err := Run(DefaultConfig, goglicko.NewDefaultSystem())
+	if err != nil {
+		t.Fatalf("Error while simulating: %v", err)
+	}
+
+	if len(report.Periods) != DefaultConfig.Periods || len(report.Players) != DefaultConfig.Players {
+		t.Fatalf("Got %v periods and %v players", len(report.Periods), len(report.Players))
+	}
+	first, last := report.Periods[0], report.Periods[len(report.Periods)-1]
+	if last.RMSE >= first.RMSE || last.MeanDeviation >= first.MeanDeviation {
+		t.Errorf("Ratings didn't converge: %+v, then %+v", first, last)
+	}
+	if last.RMSE > 100 || last.Coverage < 0.8 {
+		t.Errorf("Final ratings are off: %+v", last)
+	}
+	if report.Converged < 90 || report.GamesToConverge <= 0 {
+		t.Errorf("%v players converged after %v games on average", report.Converged, report.GamesToConverge)
+	}
+	if report.Converged+report.NotConverged != len(report.Players) {
+		t.Errorf("%v converged and %v didn't, out of %v players",
+			report.Converged, report.NotConverged, len(report.Players))
+	}
+
+	again, err := Run(DefaultConfig, goglicko.NewDefaultSystem())
+	if err != nil {
+		t.Fatalf("Error while simulating: %v", err)
+	}
+	if !reflect.DeepEqual(report.Periods, again.Periods) {
+		t.Errorf("The same seed gave different simulations")
+	}
+}
+
+func TestRunChurn(t *testing.T) {
+	c := DefaultConfig
+	c.Periods = 20
+	c.Churn = 0.1
+	c.Arrivals = 5
+	c.Drift = 20
+	c.Pairing = ClosestPairing(5)
+	c.Outcome = GaussianOutcome(200, 20)
+
+	report, err := Run(c, goglicko.NewDefaultSystem())
+	if err != nil {
+		t.Fatalf("Error while simulating: %v", err)
+	}
+
+	left, joined := 0, 0
+	for _, p := range report.Players {
+		if p.Left > 0 {
+			left++
+			if _, ok := report.Ratings[p.Name]; ok {
+				t.Errorf("%v left but is still rated", p.Name)
+			}
+		}
+		if p.Joined > 0 {
+			joined++
+		}
+	}
+	if left == 0 || joined == 0 {
+		t.Errorf("%v players left and %v joined", left, joined)
+	}
+	if active := len(report.Players) - left; active != len(report.Ratings) ||
+		active != report.Periods[len(report.Periods)-1].Players {
+		t.Errorf("%v active players, but %v ratings", active, len(report.Ratings))
+	}
+
+	draws := 0
+	for _, g := range report.Games {
+		if g.Result == goglicko.Draw {
+			draws++
+		}
+	}
+	if draws == 0 {
+		t.Errorf("No draws in %v games", len(report.Games))
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	if _, err := Run(DefaultConfig, nil); err == nil {
+		t.Errorf("Expected an error without a System")
+	}
+
+	c := DefaultConfig
+	c.Churn = 2
+	if _, err := Run(c, goglicko.NewDefaultSystem()); err == nil {
+		t.Errorf("Expected an error for churn above 1")
+	}
+}
+
+func TestPoisson(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for _, mean := range []float64{0.5, 4, 200} {
+		total := 0
+		for i := 0; i < 10000; i++ {
+			total += poisson(rnd, mean)
+		}
+		if got := float64(total) / 10000; got < mean*0.95 || got > mean*1.05 {
+			t.Errorf("Mean of poisson(%v) was %v", mean, got)
+		}
+	}
+}
@@ -0,0 +1,429 @@
+package goglicko
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Placeholder is the bind parameter syntax used by a database/sql driver. It
+// also tells the store which database it is talking to, for the few
+// statements that can't be written portably.
+type Placeholder int
+
+const (
+	QuestionPlaceholder Placeholder = iota // ?, as used by SQLite
+	DollarPlaceholder                      // $1, $2, ... as used by Postgres
+)
+
+// migration is the statements of one schema version. Most are shared by
+// every database; the few that can't be written portably have a SQLite and a
+// Postgres form, run after the shared ones.
+type migration struct {
+	shared   []string
+	sqlite   []string
+	postgres []string
+}
+
+// statements returns the statements to run on the database of placeholder.
+func (m migration) statements(placeholder Placeholder) []string {
+	stmts := append([]string(nil), m.shared...)
+	if placeholder == DollarPlaceholder {
+		return append(stmts, m.postgres...)
+	}
+	return append(stmts, m.sqlite...)
+}
+
+// The schema, one entry per version. Applied migrations are recorded in
+// schema_migrations, so entries must never be edited once released: add a new
+// version instead. The SQL is kept to what Postgres and SQLite both accept,
+// apart from the database specific statements of a migration.
+var migrations = []migration{
+	// Version 1
+	{shared: []string{
+		`CREATE TABLE players (
+			id TEXT PRIMARY KEY
+		)`,
+		`CREATE TABLE periods (
+			id BIGINT PRIMARY KEY,
+			start_at TIMESTAMP NOT NULL,
+			end_at TIMESTAMP NOT NULL
+		)`,
+		`CREATE TABLE games (
+			id BIGINT PRIMARY KEY,
+			player1 TEXT NOT NULL REFERENCES players (id),
+			player2 TEXT NOT NULL REFERENCES players (id),
+			result DOUBLE PRECISION NOT NULL,
+			played_at TIMESTAMP NOT NULL,
+			period_id BIGINT REFERENCES periods (id)
+		)`,
+		`CREATE INDEX games_played_at ON games (played_at)`,
+		`CREATE TABLE ratings (
+			player_id TEXT NOT NULL REFERENCES players (id),
+			period_id BIGINT NOT NULL REFERENCES periods (id),
+			rating DOUBLE PRECISION NOT NULL,
+			deviation DOUBLE PRECISION NOT NULL,
+			volatility DOUBLE PRECISION NOT NULL,
+			PRIMARY KEY (player_id, period_id)
+		)`,
+	}},
+	// Version 2
+	{shared: []string{
+		`ALTER TABLE games ADD COLUMN weight DOUBLE PRECISION NOT NULL DEFAULT 1`,
+	}},
+	// Version 3
+	{shared: []string{
+		`ALTER TABLE games ADD COLUMN advantage DOUBLE PRECISION NOT NULL DEFAULT 0`,
+	}},
+	// Version 4
+	{shared: []string{
+		`ALTER TABLE ratings ADD COLUMN games INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE ratings ADD COLUMN periods INTEGER NOT NULL DEFAULT 0`,
+	}},
+	// Version 5: the database assigns game and period ids. SQLite can't
+	// alter a column, so it rebuilds the tables, and the ratings table that
+	// points at periods, dropping the old ones only once nothing refers to
+	// them. Renaming a table updates the references to it.
+	{
+		sqlite: []string{
+			`CREATE TABLE periods_new (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				start_at TIMESTAMP NOT NULL,
+				end_at TIMESTAMP NOT NULL
+			)`,
+			`INSERT INTO periods_new (id, start_at, end_at) SELECT id, start_at, end_at FROM periods`,
+			`CREATE TABLE games_new (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				player1 TEXT NOT NULL REFERENCES players (id),
+				player2 TEXT NOT NULL REFERENCES players (id),
+				result DOUBLE PRECISION NOT NULL,
+				played_at TIMESTAMP NOT NULL,
+				period_id BIGINT REFERENCES periods_new (id),
+				weight DOUBLE PRECISION NOT NULL DEFAULT 1,
+				advantage DOUBLE PRECISION NOT NULL DEFAULT 0
+			)`,
+			`INSERT INTO games_new (id, player1, player2, result, played_at, period_id, weight, advantage)
+				SELECT id, player1, player2, result, played_at, period_id, weight, advantage FROM games`,
+			`CREATE TABLE ratings_new (
+				player_id TEXT NOT NULL REFERENCES players (id),
+				period_id BIGINT NOT NULL REFERENCES periods_new (id),
+				rating DOUBLE PRECISION NOT NULL,
+				deviation DOUBLE PRECISION NOT NULL,
+				volatility DOUBLE PRECISION NOT NULL,
+				games INTEGER NOT NULL DEFAULT 0,
+				periods INTEGER NOT NULL DEFAULT 0,
+				PRIMARY KEY (player_id, period_id)
+			)`,
+			`INSERT INTO ratings_new (player_id, period_id, rating, deviation, volatility, games, periods)
+				SELECT player_id, period_id, rating, deviation, volatility, games, periods FROM ratings`,
+			`DROP TABLE ratings`,
+			`DROP TABLE games`,
+			`DROP TABLE periods`,
+			`ALTER TABLE periods_new RENAME TO periods`,
+			`ALTER TABLE games_new RENAME TO games`,
+			`ALTER TABLE ratings_new RENAME TO ratings`,
+			`CREATE INDEX games_played_at ON games (played_at)`,
+		},
+		postgres: []string{
+			`ALTER TABLE periods ALTER COLUMN id ADD GENERATED BY DEFAULT AS IDENTITY`,
+			`SELECT setval(pg_get_serial_sequence('periods', 'id'), COALESCE(MAX(id), 0) + 1, false) FROM periods`,
+			`ALTER TABLE games ALTER COLUMN id ADD GENERATED BY DEFAULT AS IDENTITY`,
+			`SELECT setval(pg_get_serial_sequence('games', 'id'), COALESCE(MAX(id), 0) + 1, false) FROM games`,
+		},
+	},
+}
+
+// SQLStore is a Store backed by a database/sql database. It does not depend on
+// any particular driver; the schema and queries work with both Postgres and
+// SQLite.
+type SQLStore struct {
+	db          *sql.DB
+	system      *System // the System restored ratings belong to
+	placeholder Placeholder
+}
+
+// NewSQLStore creates a Store over db. Ratings read back from the store are
+// created with sys. Call Migrate before first use.
+func NewSQLStore(db *sql.DB, sys *System, placeholder Placeholder) *SQLStore {
+	return &SQLStore{db, sys, placeholder}
+}
+
+// bind rewrites the ? placeholders of query into the driver's syntax.
+func (s *SQLStore) bind(query string) string {
+	return bindQuery(query, s.placeholder)
+}
+
+func bindQuery(query string, placeholder Placeholder) string {
+	if placeholder != DollarPlaceholder {
+		return query
+	}
+
+	var b strings.Builder
+	n := 0
+	for _, c := range query {
+		if c == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+// Version returns the schema version of the database, 0 if it has never been
+// migrated. It doesn't change the database.
+func (s *SQLStore) Version() (int, error) {
+	exists := `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations'`
+	if s.placeholder == DollarPlaceholder {
+		exists = `SELECT COUNT(*) FROM information_schema.tables
+			WHERE table_schema = current_schema() AND table_name = 'schema_migrations'`
+	}
+	var tables int
+	if err := s.db.QueryRow(exists).Scan(&tables); err != nil {
+		return 0, err
+	}
+	if tables == 0 {
+		return 0, nil
+	}
+
+	var version sql.NullInt64
+	err := s.db.QueryRow(`SELECT MAX(version) FROM schema_migrations`).Scan(&version)
+	if err != nil {
+		return 0, err
+	}
+	return int(version.Int64), nil
+}
+
+// Migrate brings the schema up to the latest version. Each version is applied
+// in its own transaction.
+func (s *SQLStore) Migrate() error {
+	_, err := s.db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY
+	)`)
+	if err != nil {
+		return err
+	}
+
+	current, err := s.Version()
+	if err != nil {
+		return err
+	}
+	if current > len(migrations) {
+		return fmt.Errorf("Schema version %v is newer than the latest known version %v",
+			current, len(migrations))
+	}
+
+	for version := current + 1; version <= len(migrations); version++ {
+		err := s.inTx(func(tx *sql.Tx) error {
+			for _, stmt := range migrations[version-1].statements(s.placeholder) {
+				if _, err := tx.Exec(stmt); err != nil {
+					return err
+				}
+			}
+			_, err := tx.Exec(s.bind(`INSERT INTO schema_migrations (version) VALUES (?)`), version)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("Migration to version %v failed: %v", version, err)
+		}
+	}
+
+	return nil
+}
+
+// inTx runs fn in a transaction, committing if it succeeds and rolling back
+// otherwise.
+func (s *SQLStore) inTx(fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// Rating returns the most recently committed rating of a player, or nil if
+// the player has never been rated.
+func (s *SQLStore) Rating(player string) (*Rating, error) {
+	var r, rd, vol float64
+	var games, periods int
+	err := s.db.QueryRow(s.bind(`SELECT rating, deviation, volatility, games, periods FROM ratings
+		WHERE player_id = ? ORDER BY period_id DESC LIMIT 1`), player).Scan(&r, &rd, &vol, &games, &periods)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rating := NewRating(r, rd, vol, s.system)
+	rating.SetCounts(games, periods)
+	return rating, nil
+}
+
+// Ratings returns the most recently committed rating of every rated player.
+func (s *SQLStore) Ratings() (map[string]*Rating, error) {
+	rows, err := s.db.Query(`SELECT player_id, rating, deviation, volatility, games, periods FROM ratings r
+		WHERE period_id = (SELECT MAX(period_id) FROM ratings l WHERE l.player_id = r.player_id)`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ratings := make(map[string]*Rating)
+	for rows.Next() {
+		var player string
+		var r, rd, vol float64
+		var games, periods int
+		if err := rows.Scan(&player, &r, &rd, &vol, &games, &periods); err != nil {
+			return nil, err
+		}
+		ratings[player] = NewRating(r, rd, vol, s.system)
+		ratings[player].SetCounts(games, periods)
+	}
+
+	return ratings, rows.Err()
+}
+
+// History returns the rating of a player at the end of every period they were
+// rated in, timestamped with the end of the period.
+func (s *SQLStore) History(player string) (*History, error) {
+	rows, err := s.db.Query(s.bind(`SELECT p.end_at, r.rating, r.deviation, r.volatility,
+			(SELECT COUNT(*) FROM games g WHERE g.period_id = r.period_id
+				AND (g.player1 = r.player_id OR g.player2 = r.player_id))
+		FROM ratings r JOIN periods p ON p.id = r.period_id
+		WHERE r.player_id = ? ORDER BY p.end_at`), player)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	h := NewHistory()
+	for rows.Next() {
+		var snap Snapshot
+		err := rows.Scan(&snap.Time, &snap.Rating, &snap.Deviation, &snap.Volatility, &snap.Games)
+		if err != nil {
+			return nil, err
+		}
+		h.Record(snap)
+	}
+
+	return h, rows.Err()
+}
+
+// AddGame records a game, registering its players if needed. If the game has
+// no ID, the database assigns one. On Postgres, games given their own IDs
+// don't advance the generated ones, so a store should use one or the other.
+func (s *SQLStore) AddGame(g *Game) error {
+	return s.inTx(func(tx *sql.Tx) error {
+		return s.addGame(tx, g, nil)
+	})
+}
+
+func (s *SQLStore) addGame(tx *sql.Tx, g *Game, periodID *int64) error {
+	if err := g.Validate(); err != nil {
+		return err
+	}
+	for _, player := range []string{g.Player1, g.Player2} {
+		_, err := tx.Exec(s.bind(`INSERT INTO players (id) VALUES (?) ON CONFLICT DO NOTHING`), player)
+		if err != nil {
+			return err
+		}
+	}
+
+	if g.ID == 0 {
+		return tx.QueryRow(s.bind(`INSERT INTO games
+				(player1, player2, result, played_at, period_id, weight, advantage)
+			VALUES (?, ?, ?, ?, ?, ?, ?) RETURNING id`),
+			g.Player1, g.Player2, float64(g.Result), g.Played.UTC(), periodID, g.Weight, g.Advantage).Scan(&g.ID)
+	}
+
+	_, err := tx.Exec(s.bind(`INSERT INTO games
+			(id, player1, player2, result, played_at, period_id, weight, advantage)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`),
+		g.ID, g.Player1, g.Player2, float64(g.Result), g.Played.UTC(), periodID, g.Weight, g.Advantage)
+	return err
+}
+
+// Games returns the games played in [from, to), ordered by time played.
+func (s *SQLStore) Games(from, to time.Time) ([]*Game, error) {
+	rows, err := s.db.Query(s.bind(`SELECT id, player1, player2, result, played_at, weight, advantage FROM games
+		WHERE played_at >= ? AND played_at < ? ORDER BY played_at, id`), from.UTC(), to.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var games []*Game
+	for rows.Next() {
+		g := &Game{}
+		var res float64
+		if err := rows.Scan(&g.ID, &g.Player1, &g.Player2, &res, &g.Played, &g.Weight, &g.Advantage); err != nil {
+			return nil, err
+		}
+		g.Result = Result(res)
+		games = append(games, g)
+	}
+
+	return games, rows.Err()
+}
+
+// CommitPeriod atomically records a rating period, its games, and the ratings
+// of the players at the end of the period. Games that were already added are
+// assigned to the period; games without an ID are added. If the period has no
+// ID, the database assigns one, as with AddGame.
+func (s *SQLStore) CommitPeriod(p *Period, ratings map[string]*Rating) error {
+	return s.inTx(func(tx *sql.Tx) error {
+		var err error
+		if p.ID == 0 {
+			err = tx.QueryRow(s.bind(`INSERT INTO periods (start_at, end_at) VALUES (?, ?) RETURNING id`),
+				p.Start.UTC(), p.End.UTC()).Scan(&p.ID)
+		} else {
+			_, err = tx.Exec(s.bind(`INSERT INTO periods (id, start_at, end_at) VALUES (?, ?, ?)`),
+				p.ID, p.Start.UTC(), p.End.UTC())
+		}
+		if err != nil {
+			return err
+		}
+
+		for _, g := range p.Games {
+			if g.ID == 0 {
+				if err := s.addGame(tx, g, &p.ID); err != nil {
+					return err
+				}
+				continue
+			}
+
+			res, err := tx.Exec(s.bind(`UPDATE games SET period_id = ? WHERE id = ?`), p.ID, g.ID)
+			if err != nil {
+				return err
+			}
+			if n, err := res.RowsAffected(); err == nil && n == 0 {
+				return fmt.Errorf("Game %v does not exist", g.ID)
+			}
+		}
+
+		for player, r := range ratings {
+			_, err := tx.Exec(s.bind(`INSERT INTO players (id) VALUES (?) ON CONFLICT DO NOTHING`), player)
+			if err != nil {
+				return err
+			}
+			_, err = tx.Exec(s.bind(`INSERT INTO ratings
+				(player_id, period_id, rating, deviation, volatility, games, periods)
+				VALUES (?, ?, ?, ?, ?, ?, ?)`),
+				player, p.ID, r.rating, r.deviation, r.volatility, r.games, r.periods)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
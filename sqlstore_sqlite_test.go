@@ -0,0 +1,178 @@
+//go:build sqlite
+
+// Runs the SQL store against a real database. Needs the modernc.org/sqlite
+// driver: go test -tags sqlite
+
+package goglicko
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+func openSQLiteStore(t *testing.T) *SQLStore {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "ratings.db")+"?_pragma=foreign_keys(1)")
+	if err != nil {
+		t.Fatalf("Opening database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return NewSQLStore(db, NewDefaultSystem(), QuestionPlaceholder)
+}
+
+func TestSQLStoreMigrate(t *testing.T) {
+	s := openSQLiteStore(t)
+
+	if v, err := s.Version(); err != nil || v != 0 {
+		t.Fatalf("Version of a new database = %v, %v, expected 0", v, err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := s.Migrate(); err != nil {
+			t.Fatalf("Migrate %v: %v", i+1, err)
+		}
+		if v, err := s.Version(); err != nil || v != len(migrations) {
+			t.Fatalf("Version after Migrate %v = %v, %v, expected %v", i+1, v, err, len(migrations))
+		}
+	}
+}
+
+func TestSQLStoreMigrateKeepsIDs(t *testing.T) {
+	s := openSQLiteStore(t)
+	all := migrations
+	migrations = all[:4] // Before the database assigned ids
+	err := s.Migrate()
+	migrations = all
+	if err != nil {
+		t.Fatalf("Migrate to version 4: %v", err)
+	}
+	for _, q := range []string{
+		`INSERT INTO players (id) VALUES ('a'), ('b')`,
+		`INSERT INTO periods (id, start_at, end_at) VALUES (7, '2020-01-01', '2020-01-02')`,
+		`INSERT INTO games (id, player1, player2, result, played_at, period_id) VALUES (5, 'a', 'b', 1, '2020-01-01', 7)`,
+		`INSERT INTO ratings (player_id, period_id, rating, deviation, volatility) VALUES ('a', 7, 1500, 200, 0.06)`,
+	} {
+		if _, err := s.db.Exec(q); err != nil {
+			t.Fatalf("%v: %v", q, err)
+		}
+	}
+
+	if err := s.Migrate(); err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+	var games int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM games WHERE id = 5 AND period_id = 7`).Scan(&games); err != nil || games != 1 {
+		t.Errorf("Game 5 of period 7 after migrating: %v, %v", games, err)
+	}
+	if r, err := s.Rating("a"); err != nil || r == nil || r.rating != 1500 {
+		t.Errorf("Rating of a after migrating = %v, %v, expected 1500", r, err)
+	}
+	g := &Game{Player1: "a", Player2: "b", Result: Win, Played: time.Now(), Weight: 1}
+	if err := s.AddGame(g); err != nil || g.ID != 6 {
+		t.Errorf("AddGame after migrating assigned %v, %v, expected 6", g.ID, err)
+	}
+}
+
+func TestSQLStoreCommitPeriod(t *testing.T) {
+	s := openSQLiteStore(t)
+	if err := s.Migrate(); err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+	added := &Game{Player1: "a", Player2: "b", Result: Win, Played: start.Add(time.Hour), Weight: 1}
+	if err := s.AddGame(added); err != nil {
+		t.Fatalf("AddGame: %v", err)
+	}
+	if added.ID == 0 {
+		t.Fatalf("AddGame didn't assign an ID")
+	}
+
+	p := &Period{Start: start, End: end, Games: []*Game{
+		added,
+		{Player1: "b", Player2: "c", Result: Draw, Played: start.Add(2 * time.Hour), Weight: 2, Advantage: 35},
+	}}
+	ratings := make(map[string]*Rating)
+	if err := RatePeriod(ratings, p, s.system); err != nil {
+		t.Fatalf("RatePeriod: %v", err)
+	}
+	if err := s.CommitPeriod(p, ratings); err != nil {
+		t.Fatalf("CommitPeriod: %v", err)
+	}
+	if p.ID == 0 || p.Games[1].ID == 0 || p.Games[1].ID == added.ID {
+		t.Fatalf("CommitPeriod assigned period %v and game %v", p.ID, p.Games[1].ID)
+	}
+
+	games, err := s.Games(start, end)
+	if err != nil {
+		t.Fatalf("Games: %v", err)
+	}
+	if len(games) != 2 {
+		t.Fatalf("Games returned %v games, expected 2", len(games))
+	}
+	for i, g := range games {
+		exp := p.Games[i]
+		if g.ID != exp.ID || g.Player1 != exp.Player1 || g.Player2 != exp.Player2 || g.Result != exp.Result ||
+			!g.Played.Equal(exp.Played) || g.Weight != exp.Weight || g.Advantage != exp.Advantage {
+			t.Errorf("Game %v read back as %+v, expected %+v", i, g, exp)
+		}
+	}
+
+	stored, err := s.Ratings()
+	if err != nil {
+		t.Fatalf("Ratings: %v", err)
+	}
+	if len(stored) != len(ratings) {
+		t.Fatalf("Ratings returned %v players, expected %v", len(stored), len(ratings))
+	}
+	for player, exp := range ratings {
+		r, err := s.Rating(player)
+		if err != nil {
+			t.Fatalf("Rating(%v): %v", player, err)
+		}
+		for _, got := range []*Rating{r, stored[player]} {
+			if got.rating != exp.rating || got.deviation != exp.deviation || got.volatility != exp.volatility ||
+				got.Games() != exp.Games() || got.Periods() != exp.Periods() {
+				t.Errorf("%v read back as %v (%v games, %v periods), expected %v (%v games, %v periods)",
+					player, got, got.Games(), got.Periods(), exp, exp.Games(), exp.Periods())
+			}
+		}
+	}
+	if r, err := s.Rating("nobody"); r != nil || err != nil {
+		t.Errorf("Rating of an unknown player = %v, %v, expected nil", r, err)
+	}
+
+	h, err := s.History("b")
+	if err != nil {
+		t.Fatalf("History: %v", err)
+	}
+	snaps := h.Snapshots()
+	if len(snaps) != 1 || !snaps[0].Time.Equal(end) || snaps[0].Games != 2 ||
+		snaps[0].Rating != ratings["b"].rating {
+		t.Errorf("History of b = %+v, expected one snapshot of 2 games at %v", snaps, end)
+	}
+
+	// A second period with its own ID, and a game that was never added.
+	p2 := &Period{ID: 100, Start: end, End: end.Add(24 * time.Hour), Games: []*Game{
+		{ID: 1000, Player1: "a", Player2: "c", Result: Loss, Played: end.Add(time.Hour), Weight: 1},
+	}}
+	if err := s.CommitPeriod(p2, ratings); err == nil {
+		t.Errorf("CommitPeriod of a game that was never added should fail")
+	}
+	p2.Games[0].ID = 0
+	if err := RatePeriod(ratings, p2, s.system); err != nil {
+		t.Fatalf("RatePeriod: %v", err)
+	}
+	if err := s.CommitPeriod(p2, ratings); err != nil {
+		t.Fatalf("CommitPeriod: %v", err)
+	}
+	if r, err := s.Rating("a"); err != nil || r.Periods() != 2 || r.rating != ratings["a"].rating {
+		t.Errorf("Rating of a after the second period = %v, %v, expected %v over 2 periods", r, err, ratings["a"])
+	}
+	if h, err := s.History("a"); err != nil || h.Len() != 2 {
+		t.Errorf("History of a after the second period = %v, %v, expected 2 snapshots", h, err)
+	}
+}
@@ -0,0 +1,25 @@
+package goglicko
+
+import "testing"
+
+func TestBindQuery(t *testing.T) {
+	q := `SELECT a FROM t WHERE b = ? AND c < ?`
+	if got := bindQuery(q, QuestionPlaceholder); got != q {
+		t.Errorf("Question placeholders were rewritten: %v", got)
+	}
+
+	exp := `SELECT a FROM t WHERE b = $1 AND c < $2`
+	if got := bindQuery(q, DollarPlaceholder); got != exp {
+		t.Errorf("bindQuery = %v, expected %v", got, exp)
+	}
+}
+
+func TestMigrationsNotEmpty(t *testing.T) {
+	for i, m := range migrations {
+		for _, placeholder := range []Placeholder{QuestionPlaceholder, DollarPlaceholder} {
+			if len(m.statements(placeholder)) == 0 {
+				t.Errorf("Migration to version %v has no statements for placeholder %v", i+1, placeholder)
+			}
+		}
+	}
+}
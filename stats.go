@@ -0,0 +1,151 @@
+package goglicko
+
+import (
+	"math"
+	"sort"
+)
+
+// Pool computes statistics over a collection of ratings. A weighted pool
+// counts each rating by its confidence, the inverse of its variance, so
+// players the system knows little about barely move the statistics.
+//
+// The statistics of an empty pool are NaN.
+type Pool struct {
+	ratings []float64 // Ascending
+	weights []float64
+	total   float64
+}
+
+// minPoolDeviation is the smallest deviation a weighted pool counts a rating
+// with, so that a rating with no deviation at all doesn't get infinite weight.
+const minPoolDeviation = 1
+
+// NewPool creates a pool of ratings, weighted by confidence if weighted is set.
+// Deviations below a rating point are weighted as a rating point.
+func NewPool(ratings []*Rating, weighted bool) *Pool {
+	sorted := make([]*Rating, len(ratings))
+	copy(sorted, ratings)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].rating < sorted[j].rating
+	})
+
+	p := &Pool{make([]float64, len(sorted)), make([]float64, len(sorted)), 0}
+	for i, r := range sorted {
+		p.ratings[i] = r.rating
+		p.weights[i] = 1
+		if weighted {
+			p.weights[i] = 1 / sq(math.Max(r.deviation, minPoolDeviation))
+		}
+		p.total += p.weights[i]
+	}
+	return p
+}
+
+// NewPoolFromMap creates a pool of the ratings of a map of players.
+func NewPoolFromMap(ratings map[string]*Rating, weighted bool) *Pool {
+	list := make([]*Rating, 0, len(ratings))
+	for _, r := range ratings {
+		list = append(list, r)
+	}
+	return NewPool(list, weighted)
+}
+
+// Len returns the number of ratings in the pool.
+func (p *Pool) Len() int {
+	return len(p.ratings)
+}
+
+// Mean returns the mean rating.
+func (p *Pool) Mean() float64 {
+	if len(p.ratings) == 0 {
+		return math.NaN()
+	}
+
+	sum := 0.0
+	for i, r := range p.ratings {
+		sum += r * p.weights[i]
+	}
+	return sum / p.total
+}
+
+// Median returns the median rating.
+func (p *Pool) Median() float64 {
+	return p.Percentile(50)
+}
+
+// Percentile returns the rating below which q percent of the pool lies,
+// for q between 0 and 100. Each rating sits at the middle of its share of
+// the pool, and percentiles between them are interpolated linearly, so the
+// 0th and 100th percentiles are the lowest and highest ratings.
+func (p *Pool) Percentile(q float64) float64 {
+	if len(p.ratings) == 0 || q < 0 || q > 100 {
+		return math.NaN()
+	}
+
+	target := q / 100 * p.total
+	below := 0.0
+	prevPos, prevRating := 0.0, p.ratings[0]
+	for i, r := range p.ratings {
+		pos := below + p.weights[i]/2
+		if pos >= target {
+			if pos == prevPos {
+				return r
+			}
+			return prevRating + (r-prevRating)*(target-prevPos)/(pos-prevPos)
+		}
+		below += p.weights[i]
+		prevPos, prevRating = pos, r
+	}
+	return p.ratings[len(p.ratings)-1]
+}
+
+// PercentileRank returns the percentage of the pool rated below r, with
+// ratings equal to r counting half. A player with rank 97 is in the top 3%.
+func (p *Pool) PercentileRank(r *Rating) float64 {
+	if len(p.ratings) == 0 {
+		return math.NaN()
+	}
+
+	below := 0.0
+	for i, rating := range p.ratings {
+		if rating > r.rating {
+			break
+		}
+		if rating == r.rating {
+			below += p.weights[i] / 2
+		} else {
+			below += p.weights[i]
+		}
+	}
+	return 100 * below / p.total
+}
+
+// HistogramBin counts the ratings in [Lower, Upper).
+type HistogramBin struct {
+	Lower float64
+	Upper float64
+	Count int
+	Share float64 // Share of the pool, by weight
+}
+
+// Histogram returns bins of the given width covering every rating, aligned to
+// multiples of the width. Empty bins in between are included.
+func (p *Pool) Histogram(width float64) []HistogramBin {
+	if len(p.ratings) == 0 || width <= 0 {
+		return nil
+	}
+
+	first := math.Floor(p.ratings[0] / width)
+	n := int(math.Floor(p.ratings[len(p.ratings)-1]/width)-first) + 1
+	bins := make([]HistogramBin, n)
+	for i := range bins {
+		bins[i].Lower = (first + float64(i)) * width
+		bins[i].Upper = (first + float64(i+1)) * width
+	}
+	for i, r := range p.ratings {
+		b := &bins[int(math.Floor(r/width)-first)]
+		b.Count++
+		b.Share += p.weights[i] / p.total
+	}
+	return bins
+}
@@ -0,0 +1,112 @@
+package goglicko
+
+import (
+	"math"
+	"testing"
+)
+
+func poolOf(sys *System, values ...float64) []*Rating {
+	var ratings []*Rating
+	for i := 0; i < len(values); i += 2 {
+		ratings = append(ratings, NewRating(values[i], values[i+1], DefaultVol, sys))
+	}
+	return ratings
+}
+
+func TestPool(t *testing.T) {
+	sys := NewDefaultSystem()
+	p := NewPool(poolOf(sys, 1400, 50, 1600, 50, 1200, 50, 1800, 50), false)
+
+	if p.Len() != 4 || p.Mean() != 1500 || p.Median() != 1500 {
+		t.Errorf("Len %v, mean %v, median %v", p.Len(), p.Mean(), p.Median())
+	}
+
+	percentiles := []struct {
+		q, expected float64
+	}{
+		{0, 1200}, {12.5, 1200}, {25, 1300}, {75, 1700}, {87.5, 1800}, {100, 1800},
+	}
+	for _, test := range percentiles {
+		if got := p.Percentile(test.q); !floatsMostlyEqual(got, test.expected, 1e-9) {
+			t.Errorf("Percentile(%v) was %v, expected %v", test.q, got, test.expected)
+		}
+	}
+	if !math.IsNaN(p.Percentile(101)) {
+		t.Errorf("Expected NaN for a percentile above 100")
+	}
+
+	ranks := []struct {
+		rating, expected float64
+	}{
+		{1100, 0}, {1200, 12.5}, {1500, 50}, {1800, 87.5}, {1900, 100},
+	}
+	for _, test := range ranks {
+		if got := p.PercentileRank(NewRating(test.rating, 50, DefaultVol, sys)); got != test.expected {
+			t.Errorf("PercentileRank(%v) was %v, expected %v", test.rating, got, test.expected)
+		}
+	}
+}
+
+func TestPoolWeighted(t *testing.T) {
+	sys := NewDefaultSystem()
+	ratings := poolOf(sys, 1400, 50, 2000, 350)
+
+	if mean := NewPool(ratings, false).Mean(); mean != 1700 {
+		t.Errorf("Unweighted mean %v", mean)
+	}
+	weighted := NewPool(ratings, true)
+	if mean := weighted.Mean(); mean >= 1420 {
+		t.Errorf("Uncertain rating moved the weighted mean to %v", mean)
+	}
+	if rank := weighted.PercentileRank(NewRating(1500, 50, DefaultVol, sys)); rank < 95 {
+		t.Errorf("Weighted percentile rank %v", rank)
+	}
+}
+
+func TestPoolWeightedZeroDeviation(t *testing.T) {
+	sys := NewDefaultSystem()
+	ratings := poolOf(sys, 1400, 0, 1600, 0, 2000, 350)
+
+	p := NewPool(ratings, true)
+	mean := p.Mean()
+	if math.IsNaN(mean) || math.IsInf(mean, 0) || mean < 1500 || mean > 1501 {
+		t.Errorf("Mean %v of exact ratings, expected about 1500", mean)
+	}
+	if median := p.Median(); math.IsNaN(median) {
+		t.Errorf("Median of exact ratings is NaN")
+	}
+}
+
+func TestPoolHistogram(t *testing.T) {
+	sys := NewDefaultSystem()
+	p := NewPoolFromMap(map[string]*Rating{
+		"a": NewRating(1450, 50, DefaultVol, sys),
+		"b": NewRating(1510, 50, DefaultVol, sys),
+		"c": NewRating(1590, 50, DefaultVol, sys),
+		"d": NewRating(1720, 50, DefaultVol, sys),
+	}, false)
+
+	bins := p.Histogram(100)
+	expected := []HistogramBin{
+		{1400, 1500, 1, 0.25},
+		{1500, 1600, 2, 0.5},
+		{1600, 1700, 0, 0},
+		{1700, 1800, 1, 0.25},
+	}
+	if len(bins) != len(expected) {
+		t.Fatalf("Got %v bins, expected %v", bins, expected)
+	}
+	for i := range bins {
+		if bins[i] != expected[i] {
+			t.Errorf("Bin %v was %+v, expected %+v", i, bins[i], expected[i])
+		}
+	}
+}
+
+func TestEmptyPool(t *testing.T) {
+	p := NewPool(nil, true)
+	if !math.IsNaN(p.Mean()) || !math.IsNaN(p.Median()) ||
+		!math.IsNaN(p.PercentileRank(NewDefaultRating())) || p.Histogram(100) != nil {
+		t.Errorf("Expected NaN statistics for an empty pool")
+	}
+}
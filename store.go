@@ -0,0 +1,31 @@
+package goglicko
+
+import (
+	"time"
+)
+
+// Store persists players, the games they play, and their ratings at the end of
+// each rating period.
+type Store interface {
+	// Rating returns the most recently committed rating of a player, or nil if
+	// the player has never been rated.
+	Rating(player string) (*Rating, error)
+
+	// Ratings returns the most recently committed rating of every rated player.
+	Ratings() (map[string]*Rating, error)
+
+	// AddGame records a game, registering its players if needed. If the game
+	// has no ID, one is assigned.
+	AddGame(g *Game) error
+
+	// History returns the rating of a player at the end of every period they
+	// were rated in, timestamped with the end of the period.
+	History(player string) (*History, error)
+
+	// Games returns the games played in [from, to), ordered by time played.
+	Games(from, to time.Time) ([]*Game, error)
+
+	// CommitPeriod atomically records a rating period, its games, and the
+	// ratings of the players at the end of the period.
+	CommitPeriod(p *Period, ratings map[string]*Rating) error
+}
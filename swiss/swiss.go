@@ -0,0 +1,353 @@
+// Package swiss pairs Swiss-system tournaments by rating.
+//
+// Each round, players are ranked by score, then rating. Within each group of
+// equal scores the top half plays the bottom half, the first of the top half
+// against the first of the bottom half and so on; a player left over floats
+// down to the next group. Nobody plays the same opponent twice, and when the
+// ideal pairing would break that, the nearest alternative is used. With an odd
+// number of players, the lowest ranked player who hasn't had a bye gets one.
+//
+// Once the event is over, its games are rated as a single rating period.
+package swiss
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/clavoie/goglicko"
+)
+
+// Color is the side a player plays in a game.
+type Color int
+
+const (
+	White Color = iota
+	Black
+)
+
+// Pairing is a game of a round. A bye has no Black player.
+type Pairing struct {
+	White string
+	Black string
+}
+
+// Bye returns whether the pairing is a bye.
+func (p Pairing) Bye() bool {
+	return p.Black == ""
+}
+
+// Standing is a player's place in the tournament.
+type Standing struct {
+	Rank   int
+	Player string
+	Rating *goglicko.Rating // Rating the player was paired with
+	Score  float64
+	Games  int // Games played, not counting byes
+}
+
+type player struct {
+	id        string
+	rating    float64
+	r         *goglicko.Rating
+	score     float64
+	opponents map[string]bool
+	colors    []Color
+	bye       bool
+}
+
+// colorBalance returns whites less blacks played.
+func (p *player) colorBalance() int {
+	balance := 0
+	for _, c := range p.colors {
+		if c == White {
+			balance++
+		} else {
+			balance--
+		}
+	}
+	return balance
+}
+
+type game struct {
+	white, black string
+	result       goglicko.Result
+	reported     bool
+}
+
+// Tournament is a Swiss-system event.
+type Tournament struct {
+	ByePoints float64 // Score for a bye, 1 by default
+
+	system  *goglicko.System
+	players map[string]*player
+	order   []string // In order of joining
+	rounds  [][]*game
+}
+
+// NewTournament creates an event whose games are rated with sys.
+func NewTournament(sys *goglicko.System) *Tournament {
+	return &Tournament{ByePoints: 1, system: sys, players: make(map[string]*player)}
+}
+
+// Add enters a player with the rating they are paired by. Players can join
+// between rounds.
+func (t *Tournament) Add(id string, r *goglicko.Rating) error {
+	if id == "" {
+		return fmt.Errorf("Player needs an ID")
+	}
+	if _, ok := t.players[id]; ok {
+		return fmt.Errorf("Player %v already entered", id)
+	}
+
+	rating, _, _ := r.GetValues()
+	t.players[id] = &player{id: id, rating: rating, r: r, opponents: make(map[string]bool)}
+	t.order = append(t.order, id)
+	return nil
+}
+
+// Rounds returns the number of rounds paired so far.
+func (t *Tournament) Rounds() int {
+	return len(t.rounds)
+}
+
+// ranked returns the players by score, then rating, then ID.
+func (t *Tournament) ranked() []*player {
+	ranked := make([]*player, 0, len(t.order))
+	for _, id := range t.order {
+		ranked = append(ranked, t.players[id])
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		if a.score != b.score {
+			return a.score > b.score
+		}
+		if a.rating != b.rating {
+			return a.rating > b.rating
+		}
+		return a.id < b.id
+	})
+	return ranked
+}
+
+// Pair pairs the next round. Every result of the previous round must have
+// been reported.
+func (t *Tournament) Pair() ([]Pairing, error) {
+	if n := len(t.rounds); n > 0 {
+		for _, g := range t.rounds[n-1] {
+			if !g.reported {
+				return nil, fmt.Errorf("Round %v is missing the result of %v against %v", n, g.white, g.black)
+			}
+		}
+	}
+	ranked := t.ranked()
+	if len(ranked) < 2 {
+		return nil, fmt.Errorf("Need at least 2 players to pair, have %v", len(ranked))
+	}
+
+	var pairs [][2]*player
+	bye := ""
+	steps := maxPairSteps
+	if len(ranked)%2 == 0 {
+		pairs = pair(ranked, &steps)
+	} else {
+		byes := 0
+		for i := len(ranked) - 1; i >= 0 && pairs == nil && steps > 0; i-- {
+			if ranked[i].bye {
+				byes++
+				continue
+			}
+			rest := append(append([]*player(nil), ranked[:i]...), ranked[i+1:]...)
+			if pairs = pair(rest, &steps); pairs != nil {
+				bye = ranked[i].id
+			}
+		}
+		if byes == len(ranked) {
+			return nil, fmt.Errorf("Round %v needs a bye, but every player has had one", len(t.rounds)+1)
+		}
+	}
+	if pairs == nil && steps <= 0 {
+		return nil, fmt.Errorf("Round %v couldn't be paired in %v steps", len(t.rounds)+1, maxPairSteps)
+	}
+	if pairs == nil {
+		return nil, fmt.Errorf("Round %v can't be paired without a repeat game", len(t.rounds)+1)
+	}
+
+	round := len(t.rounds)
+	var games []*game
+	var pairings []Pairing
+	for _, p := range pairs {
+		white, black := p[0], p[1]
+		if prefersBlack(white, black, round) {
+			white, black = black, white
+		}
+		white.opponents[black.id] = true
+		black.opponents[white.id] = true
+		white.colors = append(white.colors, White)
+		black.colors = append(black.colors, Black)
+
+		games = append(games, &game{white: white.id, black: black.id})
+		pairings = append(pairings, Pairing{white.id, black.id})
+	}
+	if bye != "" {
+		t.players[bye].bye = true
+		t.players[bye].score += t.ByePoints
+		pairings = append(pairings, Pairing{White: bye})
+	}
+
+	t.rounds = append(t.rounds, games)
+	return pairings, nil
+}
+
+// maxPairSteps bounds the search for a round's pairings. A real event finds
+// its pairings in a few hundred steps; the bound stops a round that can't be
+// paired from searching every combination of its players.
+const maxPairSteps = 1000000
+
+// pair pairs ranked players, higher ranked first in each pair, or returns nil
+// if they can't be paired without a repeat game or the search ran out of
+// steps. Alternatives are searched depth first, which is quick for the nearly
+// valid pairings of a real event.
+func pair(ranked []*player, steps *int) [][2]*player {
+	if len(ranked) == 0 {
+		return [][2]*player{}
+	}
+	if *steps--; *steps < 0 {
+		return nil
+	}
+
+	// The top player's score group, and their ideal opponent in it: the
+	// first of the group's bottom half.
+	top := ranked[0]
+	group := 1
+	for group < len(ranked) && ranked[group].score == top.score {
+		group++
+	}
+	half := group / 2
+
+	var candidates []int
+	for i := half; i < group; i++ {
+		candidates = append(candidates, i)
+	}
+	for i := half - 1; i >= 1; i-- {
+		candidates = append(candidates, i)
+	}
+	for i := group; i < len(ranked); i++ {
+		candidates = append(candidates, i)
+	}
+
+	for _, c := range candidates {
+		opp := ranked[c]
+		if c == 0 || top.opponents[opp.id] {
+			continue
+		}
+		rest := make([]*player, 0, len(ranked)-2)
+		for i, p := range ranked {
+			if i != 0 && i != c {
+				rest = append(rest, p)
+			}
+		}
+		if pairs := pair(rest, steps); pairs != nil {
+			return append([][2]*player{{top, opp}}, pairs...)
+		}
+		if *steps < 0 {
+			return nil
+		}
+	}
+	return nil
+}
+
+// prefersBlack returns whether a should rather play black against b: a has
+// played white more often, or as often but played white last. Otherwise the
+// higher ranked a alternates colors by round.
+func prefersBlack(a, b *player, round int) bool {
+	if ab, bb := a.colorBalance(), b.colorBalance(); ab != bb {
+		return ab > bb
+	}
+	if len(a.colors) > 0 && len(b.colors) > 0 {
+		al, bl := a.colors[len(a.colors)-1], b.colors[len(b.colors)-1]
+		if al != bl {
+			return al == White
+		}
+	}
+	return round%2 == 1
+}
+
+// Result reports the result of a game of the current round, from White's
+// point of view.
+func (t *Tournament) Result(white string, res goglicko.Result) error {
+	if len(t.rounds) == 0 {
+		return fmt.Errorf("No round has been paired")
+	}
+	if math.IsNaN(float64(res)) || res < goglicko.Loss || res > goglicko.Win {
+		return fmt.Errorf("Result %v outside of [%v, %v]", res, goglicko.Loss, goglicko.Win)
+	}
+
+	for _, g := range t.rounds[len(t.rounds)-1] {
+		if g.white != white {
+			continue
+		}
+		if g.reported {
+			t.players[g.white].score -= float64(g.result)
+			t.players[g.black].score -= float64(g.result.Opposite())
+		}
+		g.result, g.reported = res, true
+		t.players[g.white].score += float64(res)
+		t.players[g.black].score += float64(res.Opposite())
+		return nil
+	}
+	return fmt.Errorf("%v doesn't play white in round %v", white, len(t.rounds))
+}
+
+// Standings returns every player, best first, ranked by score, then rating.
+// Players with equal scores and ratings share a rank.
+func (t *Tournament) Standings() []Standing {
+	var standings []Standing
+	ranked := t.ranked()
+	for i, p := range ranked {
+		s := Standing{Rank: i + 1, Player: p.id, Rating: p.r, Score: p.score, Games: len(p.colors)}
+		if i > 0 && ranked[i-1].score == p.score && ranked[i-1].rating == p.rating {
+			s.Rank = standings[i-1].Rank
+		}
+		standings = append(standings, s)
+	}
+	return standings
+}
+
+// Period returns the reported games of the event as a rating period from
+// start to end, with White as the first player. Byes aren't games and are
+// left out.
+func (t *Tournament) Period(start, end time.Time) *goglicko.Period {
+	p := &goglicko.Period{Start: start, End: end}
+	for _, round := range t.rounds {
+		for _, g := range round {
+			if g.reported {
+				p.Games = append(p.Games, &goglicko.Game{
+					ID:      int64(len(p.Games) + 1),
+					Player1: g.white,
+					Player2: g.black,
+					Result:  g.result,
+					Played:  start,
+					Weight:  1,
+				})
+			}
+		}
+	}
+	return p
+}
+
+// Rate rates the event as a single rating period from start to end, updating
+// ratings as goglicko.RatePeriod does. Players who played but are missing from
+// ratings are added, rated from a copy of the rating they entered with.
+func (t *Tournament) Rate(ratings map[string]*goglicko.Rating, start, end time.Time) error {
+	p := t.Period(start, end)
+	for _, g := range p.Games {
+		for _, id := range []string{g.Player1, g.Player2} {
+			if _, ok := ratings[id]; !ok {
+				ratings[id] = t.players[id].r.Copy()
+			}
+		}
+	}
+	return goglicko.RatePeriod(ratings, p, t.system)
+}
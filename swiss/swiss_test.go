@@ -0,0 +1,281 @@
+package swiss
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/clavoie/goglicko"
+)
+
+var sys = goglicko.NewDefaultSystem()
+
+func newTournament(t *testing.T, ratings ...float64) *Tournament {
+	tour := NewTournament(sys)
+	for i, r := range ratings {
+		id := string(rune('a' + i))
+		if err := tour.Add(id, goglicko.NewRating(r, 50, goglicko.DefaultVol, sys)); err != nil {
+			t.Fatalf("Error while adding %v: %v", id, err)
+		}
+	}
+	return tour
+}
+
+func pairings(t *testing.T, tour *Tournament) string {
+	pairings, err := tour.Pair()
+	if err != nil {
+		t.Fatalf("Error while pairing: %v", err)
+	}
+	s := ""
+	for _, p := range pairings {
+		if p.Bye() {
+			s += fmt.Sprintf("%v-bye ", p.White)
+		} else {
+			s += fmt.Sprintf("%v-%v ", p.White, p.Black)
+		}
+	}
+	return s
+}
+
+func report(t *testing.T, tour *Tournament, white string, res goglicko.Result) {
+	if err := tour.Result(white, res); err != nil {
+		t.Fatalf("Error while reporting: %v", err)
+	}
+}
+
+func TestPairRounds(t *testing.T) {
+	tour := newTournament(t, 2000, 1900, 1800, 1700)
+
+	if got := pairings(t, tour); got != "a-c b-d " {
+		t.Fatalf("Round 1 paired %q", got)
+	}
+	if _, err := tour.Pair(); err == nil {
+		t.Errorf("Expected an error pairing before the results are in")
+	}
+	report(t, tour, "a", goglicko.Win)
+	report(t, tour, "b", goglicko.Loss)
+
+	// a and d lead; both play the color they didn't have.
+	if got := pairings(t, tour); got != "d-a c-b " {
+		t.Fatalf("Round 2 paired %q", got)
+	}
+	report(t, tour, "d", goglicko.Loss)
+	report(t, tour, "c", goglicko.Draw)
+
+	// a already played d, the next in the score order, so plays b.
+	if got := pairings(t, tour); got != "a-b d-c " {
+		t.Fatalf("Round 3 paired %q", got)
+	}
+	report(t, tour, "a", goglicko.Draw)
+	report(t, tour, "d", goglicko.Draw)
+
+	standings := tour.Standings()
+	got := ""
+	for _, s := range standings {
+		got += fmt.Sprintf("%v:%v:%v ", s.Rank, s.Player, s.Score)
+	}
+	if got != "1:a:2.5 2:d:1.5 3:b:1 4:c:1 " {
+		t.Errorf("Standings %q", got)
+	}
+
+	if _, err := tour.Pair(); err == nil {
+		t.Errorf("Expected an error once everyone played everyone")
+	}
+}
+
+func TestPairByes(t *testing.T) {
+	tour := newTournament(t, 2000, 1900, 1800, 1700, 1600)
+
+	if got := pairings(t, tour); got != "a-c b-d e-bye " {
+		t.Fatalf("Round 1 paired %q", got)
+	}
+	report(t, tour, "a", goglicko.Loss)
+	report(t, tour, "b", goglicko.Loss)
+
+	// c, d and e lead with a point; c plays d and e floats down to a. e
+	// already had a bye, so b, last, gets it.
+	if got := pairings(t, tour); got != "d-c e-a b-bye " {
+		t.Errorf("Round 2 paired %q", got)
+	}
+}
+
+func TestNoRepeats(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	var ratings []float64
+	for i := 0; i < 15; i++ {
+		ratings = append(ratings, 1500+rnd.NormFloat64()*200)
+	}
+	tour := newTournament(t, ratings...)
+
+	played := map[[2]string]bool{}
+	byes := map[string]bool{}
+	for round := 1; round <= 7; round++ {
+		pairings, err := tour.Pair()
+		if err != nil {
+			t.Fatalf("Error while pairing round %v: %v", round, err)
+		}
+		if len(pairings) != 8 {
+			t.Errorf("Round %v has %v pairings", round, len(pairings))
+		}
+		for _, p := range pairings {
+			if p.Bye() {
+				if byes[p.White] {
+					t.Errorf("%v had a second bye in round %v", p.White, round)
+				}
+				byes[p.White] = true
+				continue
+			}
+			key := [2]string{p.White, p.Black}
+			if p.Black < p.White {
+				key = [2]string{p.Black, p.White}
+			}
+			if played[key] {
+				t.Errorf("%v played %v again in round %v", p.White, p.Black, round)
+			}
+			played[key] = true
+			report(t, tour, p.White, goglicko.Result(float64(rnd.Intn(3))/2))
+		}
+	}
+
+	for _, s := range tour.Standings() {
+		white := 0
+		for _, c := range tour.players[s.Player].colors {
+			if c == White {
+				white++
+			}
+		}
+		if diff := 2*white - s.Games; diff > 2 || diff < -2 {
+			t.Errorf("%v played white %v times in %v games", s.Player, white, s.Games)
+		}
+	}
+}
+
+func TestRate(t *testing.T) {
+	tour := newTournament(t, 1600, 1500, 1400)
+	pairings(t, tour)
+	report(t, tour, "a", goglicko.Loss)
+
+	start := time.Date(2017, time.March, 4, 0, 0, 0, 0, time.UTC)
+	p := tour.Period(start, start.Add(48*time.Hour))
+	if len(p.Games) != 1 || p.Games[0].Player1 != "a" || p.Games[0].Player2 != "b" || !p.Games[0].Played.Equal(start) {
+		t.Fatalf("Period games %+v", p.Games)
+	}
+
+	ratings := map[string]*goglicko.Rating{"a": goglicko.NewRating(1600, 50, goglicko.DefaultVol, sys)}
+	if err := tour.Rate(ratings, start, start.Add(48*time.Hour)); err != nil {
+		t.Fatalf("Error while rating: %v", err)
+	}
+	if r, _, _ := ratings["a"].GetValues(); r >= 1600 {
+		t.Errorf("Rating of a rose to %v after a loss", r)
+	}
+	// b wasn't in ratings, so is rated from the 1500 they entered with.
+	if r, _, _ := ratings["b"].GetValues(); r <= 1500 || r > 1530 {
+		t.Errorf("b entered at 1500 rated %v after a win", r)
+	}
+	if ratings["b"] == tour.players["b"].r {
+		t.Errorf("Rating b entered with was updated in place")
+	}
+	if _, ok := ratings["c"]; ok {
+		t.Errorf("c only had a bye and shouldn't be rated")
+	}
+}
+
+func TestStandingsTies(t *testing.T) {
+	tour := newTournament(t, 1500, 1500, 1400, 1400)
+	pairings(t, tour)
+	report(t, tour, "a", goglicko.Draw)
+	report(t, tour, "b", goglicko.Draw)
+
+	// Ratings changing outside the event don't change how players were
+	// ranked by it.
+	tour.players["a"].r.Update([]*goglicko.Rating{goglicko.NewDefaultRating()}, []goglicko.Result{goglicko.Win})
+
+	got := ""
+	for _, s := range tour.Standings() {
+		got += fmt.Sprintf("%v:%v ", s.Rank, s.Player)
+	}
+	if got != "1:a 1:b 3:c 3:d " {
+		t.Errorf("Standings %q", got)
+	}
+}
+
+func TestPairEveryoneHadBye(t *testing.T) {
+	tour := newTournament(t, 1600, 1500, 1400)
+	for round := 1; round <= 3; round++ {
+		pairings(t, tour)
+		for _, g := range tour.rounds[len(tour.rounds)-1] {
+			report(t, tour, g.white, goglicko.Draw)
+		}
+	}
+
+	_, err := tour.Pair()
+	if err == nil || !strings.Contains(err.Error(), "bye") {
+		t.Errorf("Pairing after everyone had a bye returned %v", err)
+	}
+}
+
+func TestPairLargeEvent(t *testing.T) {
+	rnd := rand.New(rand.NewSource(2))
+	var ratings []float64
+	for i := 0; i < 33; i++ {
+		ratings = append(ratings, 1500+rnd.NormFloat64()*200)
+	}
+	tour := NewTournament(sys)
+	for i, r := range ratings {
+		tour.Add(fmt.Sprintf("p%02d", i), goglicko.NewRating(r, 50, goglicko.DefaultVol, sys))
+	}
+
+	for round := 1; round <= 9; round++ {
+		if _, err := tour.Pair(); err != nil {
+			t.Fatalf("Error while pairing round %v: %v", round, err)
+		}
+		for _, g := range tour.rounds[len(tour.rounds)-1] {
+			report(t, tour, g.white, goglicko.Result(float64(rnd.Intn(3))/2))
+		}
+	}
+}
+
+func BenchmarkPair(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		rnd := rand.New(rand.NewSource(3))
+		tour := NewTournament(sys)
+		for j := 0; j < 64; j++ {
+			tour.Add(fmt.Sprint(j), goglicko.NewRating(1500+rnd.NormFloat64()*200, 50, goglicko.DefaultVol, sys))
+		}
+		for round := 1; round <= 9; round++ {
+			if _, err := tour.Pair(); err != nil {
+				b.Fatalf("Error while pairing round %v: %v", round, err)
+			}
+			for _, g := range tour.rounds[len(tour.rounds)-1] {
+				tour.Result(g.white, goglicko.Result(float64(rnd.Intn(3))/2))
+			}
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	tour := newTournament(t, 1500)
+	if err := tour.Add("a", goglicko.NewDefaultRating()); err == nil {
+		t.Errorf("Expected an error adding a twice")
+	}
+	if err := tour.Result("a", goglicko.Win); err == nil {
+		t.Errorf("Expected an error reporting before pairing")
+	}
+	if _, err := tour.Pair(); err == nil {
+		t.Errorf("Expected an error pairing a single player")
+	}
+
+	tour.Add("b", goglicko.NewDefaultRating())
+	pairings(t, tour)
+	if err := tour.Result("b", goglicko.Win); err == nil {
+		t.Errorf("Expected an error reporting for black")
+	}
+	for _, res := range []goglicko.Result{goglicko.Result(math.NaN()), 1.5, -0.5} {
+		if err := tour.Result("a", res); err == nil {
+			t.Errorf("Expected an error reporting %v", res)
+		}
+	}
+}
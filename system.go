@@ -6,6 +6,11 @@ type System struct {
 	baseDeviation  float64
 	baseVolatility float64
 	tau            float64 // constrains system volatility, should be between 0.3-1.2
+
+	// ratings are provisional above this deviation or below this many games,
+	// see SetProvisional
+	provisionalDeviation float64
+	provisionalGames     int
 }
 
 // NewDefaultSystem creates a new System using DefaultRat, DefaultDev, DefaultVol, and DefaultTau
@@ -15,10 +20,31 @@ func NewDefaultSystem() *System {
 
 // NewSystem creates a custom System
 func NewSystem(baseRating, baseDeviation, baseVolitility, tau float64) *System {
-	return &System{baseRating, baseDeviation, baseVolitility, tau}
+	return &System{baseRating, baseDeviation, baseVolitility, tau, 0, 0}
 }
 
 // GetValues returns the base rating, deviation, volatility, and tau
 func (s *System) GetValues() (float64, float64, float64, float64) {
 	return s.baseRating, s.baseDeviation, s.baseVolatility, s.tau
 }
+
+// NewRating creates the starting Rating of a new player in this System
+func (s *System) NewRating() *Rating {
+	return NewRating(s.baseRating, s.baseDeviation, s.baseVolatility, s)
+}
+
+// SetProvisional sets when the ratings of this System are provisional: while
+// their deviation is above maxDeviation, or they have played fewer than
+// minGames games. A zero value turns that check off; both are off by default.
+// The System is changed in place, so every Rating sharing it follows the new
+// setting, including ratings created before the call.
+func (s *System) SetProvisional(maxDeviation float64, minGames int) {
+	s.provisionalDeviation = maxDeviation
+	s.provisionalGames = minGames
+}
+
+// GetProvisional returns the maximum deviation and minimum games of ratings
+// that aren't provisional, see SetProvisional
+func (s *System) GetProvisional() (float64, int) {
+	return s.provisionalDeviation, s.provisionalGames
+}
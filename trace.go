@@ -0,0 +1,87 @@
+package goglicko
+
+// OpponentTrace is what one opponent contributed to an update, on the Glicko2
+// scale.
+type OpponentTrace struct {
+	Mu     float64 // Opponent's rating
+	Phi    float64 // Opponent's deviation
+	Result Result
+	Weight float64
+	G      float64 // g(phi_j)
+	E      float64 // E(mu, mu_j, phi_j), the expected score against the opponent
+}
+
+// Trace records the intermediate values of an update, following the steps
+// documented at the top of goglicko.go. Greek letters are on the Glicko2
+// scale; Rating, Deviation and Volatility are on the Glicko1 scale.
+type Trace struct {
+	// Step 1: initial values.
+	Rating     float64
+	Deviation  float64
+	Volatility float64
+	Tau        float64
+
+	// Step 2: conversion to the Glicko2 scale.
+	Mu        float64
+	Phi       float64
+	Sigma     float64
+	Opponents []OpponentTrace
+
+	// Step 3: estimated variance, v. Zero if the player sat out the period.
+	Variance float64
+
+	// Step 4: estimated improvement, Delta.
+	Delta float64
+
+	// Step 5: the new volatility, sigma', and its estimate after each
+	// iteration of the solver. If the solver ran out of iterations before
+	// converging, the last estimate is used.
+	NewVolatility  float64
+	VolatilityPath []float64
+	Iterations     int
+	Converged      bool
+
+	// Step 6: the pre-rating period deviation, phi*.
+	PrePeriodPhi float64
+
+	// Step 7: the new rating and deviation, mu' and phi'.
+	NewMu  float64
+	NewPhi float64
+
+	// Step 8: the new values on the Glicko1 scale. The volatility is the
+	// same on both scales. The deviation is capped at the System's base
+	// deviation; PreClampDeviation is its value before the cap.
+	NewRating         float64
+	NewDeviation      float64
+	PreClampDeviation float64
+	Capped            bool
+}
+
+// start records Steps 1 and 2 for player, whose Glicko2 scaled values are p2.
+func (t *Trace) start(player, p2 *Rating) {
+	t.Rating, t.Deviation, t.Volatility = player.rating, player.deviation, player.volatility
+	t.Tau = player.system.tau
+	t.Mu, t.Phi, t.Sigma = p2.rating, p2.deviation, p2.volatility
+}
+
+// finish records Step 8 from the updated player.
+func (t *Trace) finish(player *Rating, preClamp float64, capped bool) {
+	t.NewRating, t.NewDeviation = player.rating, player.deviation
+	t.PreClampDeviation, t.Capped = preClamp, capped
+}
+
+// Explain works out the update Update would make, without changing the
+// rating, and returns the intermediate values of every step.
+func (player *Rating) Explain(opponents []*Rating, res []Result) (*Trace, error) {
+	return player.ExplainWeighted(opponents, res, nil)
+}
+
+// ExplainWeighted is Explain with each game counting weights[i] times, as the
+// Game Weights do in RatePeriod. A nil weights counts every game once.
+func (player *Rating) ExplainWeighted(opponents []*Rating, res []Result, weights []float64) (*Trace, error) {
+	trace := &Trace{}
+	if err := player.Copy().update(opponents, res, weights, trace); err != nil {
+		return nil, err
+	}
+	return trace, nil
+}
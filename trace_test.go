@@ -0,0 +1,114 @@
+package goglicko
+
+import "testing"
+
+func TestExplain(t *testing.T) {
+	// The example from the Glicko2 paper, as in TestGlicko.
+	sys := NewDefaultSystem()
+	pl := NewRating(1500, 200, DefaultVol, sys)
+	opps := []*Rating{
+		NewRating(1400, 30, DefaultVol, sys),
+		NewRating(1550, 100, DefaultVol, sys),
+		NewRating(1700, 300, DefaultVol, sys),
+	}
+
+	trace, err := pl.Explain(opps, []Result{1, 0, 0})
+	if err != nil {
+		t.Fatalf("Error while explaining: %v", err)
+	}
+	if pl.rating != 1500 || pl.deviation != 200 {
+		t.Errorf("Explain changed the rating: %v", pl)
+	}
+
+	checks := []struct {
+		name     string
+		got, exp float64
+		epsilon  float64
+	}{
+		{"Mu", trace.Mu, 0, 0.0001},
+		{"Phi", trace.Phi, 1.1513, 0.0001},
+		{"Opponents[0].G", trace.Opponents[0].G, 0.9955, 0.0001},
+		{"Opponents[2].E", trace.Opponents[2].E, 0.303, 0.001},
+		{"Variance", trace.Variance, 1.7785, 0.001},
+		{"Delta", trace.Delta, -0.4834, 0.001},
+		{"NewVolatility", trace.NewVolatility, 0.05999, 0.0001},
+		{"PrePeriodPhi", trace.PrePeriodPhi, 1.152862, 0.0001},
+		{"NewMu", trace.NewMu, -0.2069, 0.0001},
+		{"NewPhi", trace.NewPhi, 0.8722, 0.0001},
+		{"NewRating", trace.NewRating, 1464.06, 0.01},
+		{"NewDeviation", trace.NewDeviation, 151.52, 0.01},
+	}
+	for _, c := range checks {
+		if !floatsMostlyEqual(c.got, c.exp, c.epsilon) {
+			t.Errorf("%v %v != expected %v", c.name, c.got, c.exp)
+		}
+	}
+
+	if n := len(trace.VolatilityPath); n == 0 || trace.VolatilityPath[n-1] != trace.NewVolatility {
+		t.Errorf("VolatilityPath %v doesn't end at the new volatility", trace.VolatilityPath)
+	}
+}
+
+func TestExplainIdle(t *testing.T) {
+	pl := NewRating(1500, 200, DefaultVol, NewDefaultSystem())
+	trace, err := pl.Explain(nil, nil)
+	if err != nil {
+		t.Fatalf("Error while explaining: %v", err)
+	}
+	if trace.NewRating != 1500 || !floatsMostlyEqual(trace.NewDeviation, 200.27, 0.01) {
+		t.Errorf("Idle trace %+v", trace)
+	}
+}
+
+func TestUpdateWithTrace(t *testing.T) {
+	sys := NewDefaultSystem()
+	pl := NewRating(1500, 200, DefaultVol, sys)
+	opps := []*Rating{NewRating(1400, 30, DefaultVol, sys)}
+
+	trace, err := pl.UpdateWithTrace(opps, []Result{Win})
+	if err != nil {
+		t.Fatalf("Error while updating: %v", err)
+	}
+	if trace.NewRating != pl.rating || trace.NewDeviation != pl.deviation || trace.NewVolatility != pl.volatility {
+		t.Errorf("Trace %+v doesn't match the updated rating %v", trace, pl)
+	}
+	if !trace.Converged || trace.Iterations != len(trace.VolatilityPath) {
+		t.Errorf("Solver didn't converge or iterations not counted: %+v", trace)
+	}
+	if trace.Capped || trace.PreClampDeviation != pl.deviation {
+		t.Errorf("Deviation capped when it shouldn't be: %+v", trace)
+	}
+
+	// A newcomer sitting out a period grows past the base deviation.
+	newcomer := sys.NewRating()
+	trace, err = newcomer.UpdateWithTrace(nil, nil)
+	if err != nil {
+		t.Fatalf("Error while updating: %v", err)
+	}
+	if !trace.Capped || trace.PreClampDeviation <= DefaultDev || newcomer.deviation != DefaultDev {
+		t.Errorf("Deviation not capped: %+v", trace)
+	}
+}
+
+func TestExplainWeighted(t *testing.T) {
+	sys := NewDefaultSystem()
+	pl := NewRating(1500, 200, DefaultVol, sys)
+	opps := []*Rating{NewRating(1400, 30, DefaultVol, sys), NewRating(1550, 100, DefaultVol, sys)}
+	res := []Result{Win, Loss}
+	weights := []float64{2, 0.5}
+
+	trace, err := pl.ExplainWeighted(opps, res, weights)
+	if err != nil {
+		t.Fatalf("Error while explaining: %v", err)
+	}
+	exp := pl.Copy()
+	if err := exp.update(opps, res, weights, nil); err != nil {
+		t.Fatalf("Error while updating: %v", err)
+	}
+	if trace.NewRating != exp.rating || trace.NewDeviation != exp.deviation || trace.Opponents[1].Weight != 0.5 {
+		t.Errorf("Trace %+v doesn't match the weighted update %v", trace, exp)
+	}
+	if _, err := pl.ExplainWeighted(opps, res, weights[:1]); err == nil {
+		t.Errorf("Expected an error for mismatched weights")
+	}
+}
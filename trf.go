@@ -0,0 +1,261 @@
+package goglicko
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Column ranges of a FIDE TRF player record ("001" line), 0 based and half
+// open. Round results follow at trfRoundStart, trfRoundWidth columns each, as
+// do the round dates of the "132" line.
+const (
+	trfRankStart, trfRankEnd     = 4, 8
+	trfNameStart, trfNameEnd     = 14, 47
+	trfRatingStart, trfRatingEnd = 48, 52
+	trfIDStart, trfIDEnd         = 57, 68
+	trfPointsStart, trfPointsEnd = 80, 84
+	trfRoundStart, trfRoundWidth = 91, 10
+)
+
+// Record types, the first three columns of a line.
+const (
+	trfPlayerPrefix     = "001"
+	trfStartDatePrefix  = "042"
+	trfEndDatePrefix    = "052"
+	trfRoundDatesPrefix = "132"
+)
+
+const (
+	trfTournamentDateLayout = "2006/01/02"
+	trfRoundDateLayout      = "06/01/02"
+)
+
+// TRFRound is one round of a player's record.
+type TRFRound struct {
+	Opponent int  // Starting rank of the opponent, 0 for a bye
+	Color    byte // 'w', 'b' or '-'
+	// Result as written: '1', '0' and '=' for rated games, '+' and '-' for
+	// forfeits, 'W', 'D' and 'L' for unrated games, and 'H', 'F', 'U' or 'Z'
+	// for byes.
+	Result byte
+}
+
+// Rated returns whether the round is a game that was played and counts for
+// rating.
+func (r TRFRound) Rated() bool {
+	return r.Opponent != 0 && (r.Result == '1' || r.Result == '0' || r.Result == '=')
+}
+
+// TRFPlayer is a player record of a TRF file.
+type TRFPlayer struct {
+	StartRank int
+	Name      string
+	Rating    int // Rating before the tournament, 0 if unrated
+	FideID    string
+	Points    float64
+	Rounds    []TRFRound
+
+	line int // index of the record in TRFTournament.lines
+}
+
+// ID returns the key the player is rated under: the FIDE ID if they have one,
+// their name otherwise.
+func (p *TRFPlayer) ID() string {
+	if p.FideID != "" {
+		return p.FideID
+	}
+	return p.Name
+}
+
+// TRFTournament is a tournament report in FIDE's Tournament Report File
+// format, as written by pairing software.
+type TRFTournament struct {
+	Start      time.Time   // 042 line, zero if missing
+	End        time.Time   // 052 line, zero if missing
+	RoundDates []time.Time // 132 line, zero where missing
+	Players    []*TRFPlayer
+
+	lines []string // the file as read, rewritten by Write
+}
+
+// ReadTRF reads a tournament report.
+func ReadTRF(r io.Reader) (*TRFTournament, error) {
+	t := &TRFTournament{}
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		line := strings.TrimRight(s.Text(), "\r")
+		t.lines = append(t.lines, line)
+
+		var err error
+		switch {
+		case strings.HasPrefix(line, trfPlayerPrefix):
+			err = t.readPlayer(line, len(t.lines)-1)
+		case strings.HasPrefix(line, trfStartDatePrefix):
+			t.Start, err = parseTRFDate(trfTournamentDateLayout, trfField(line, 4, len(line)))
+		case strings.HasPrefix(line, trfEndDatePrefix):
+			t.End, err = parseTRFDate(trfTournamentDateLayout, trfField(line, 4, len(line)))
+		case strings.HasPrefix(line, trfRoundDatesPrefix):
+			for start := trfRoundStart; start < len(line); start += trfRoundWidth {
+				d, err := parseTRFDate(trfRoundDateLayout, trfField(line, start, start+8))
+				if err != nil {
+					return nil, fmt.Errorf("Line %v: %v", len(t.lines), err)
+				}
+				t.RoundDates = append(t.RoundDates, d)
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Line %v: %v", len(t.lines), err)
+		}
+	}
+
+	return t, s.Err()
+}
+
+func (t *TRFTournament) readPlayer(line string, index int) error {
+	p := &TRFPlayer{
+		Name:   trfField(line, trfNameStart, trfNameEnd),
+		FideID: trfField(line, trfIDStart, trfIDEnd),
+		line:   index,
+	}
+
+	var err error
+	if p.StartRank, err = strconv.Atoi(trfField(line, trfRankStart, trfRankEnd)); err != nil {
+		return fmt.Errorf("Invalid starting rank: %v", err)
+	}
+	if rating := trfField(line, trfRatingStart, trfRatingEnd); rating != "" {
+		if p.Rating, err = strconv.Atoi(rating); err != nil {
+			return fmt.Errorf("Invalid rating: %v", err)
+		}
+	}
+	if points := trfField(line, trfPointsStart, trfPointsEnd); points != "" {
+		if p.Points, err = strconv.ParseFloat(points, 64); err != nil {
+			return fmt.Errorf("Invalid points: %v", err)
+		}
+	}
+
+	for start := trfRoundStart; start < len(line); start += trfRoundWidth {
+		var round TRFRound
+		if opp := trfField(line, start, start+4); opp != "" {
+			if round.Opponent, err = strconv.Atoi(opp); err != nil {
+				return fmt.Errorf("Invalid opponent in round %v: %v", len(p.Rounds)+1, err)
+			}
+		}
+		round.Color = trfByte(line, start+5)
+		round.Result = trfByte(line, start+7)
+		p.Rounds = append(p.Rounds, round)
+	}
+
+	t.Players = append(t.Players, p)
+	return nil
+}
+
+// trfField returns the trimmed columns [start, end) of line, or as many of
+// them as the line has.
+func trfField(line string, start, end int) string {
+	if start >= len(line) {
+		return ""
+	}
+	if end > len(line) {
+		end = len(line)
+	}
+	return strings.TrimSpace(line[start:end])
+}
+
+func trfByte(line string, i int) byte {
+	if i >= len(line) {
+		return ' '
+	}
+	return line[i]
+}
+
+func parseTRFDate(layout, s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(layout, s)
+}
+
+// Games returns the rated games of the tournament, each once, with White as
+// Player1. Games are dated by the round dates if the report has them, and the
+// end of the tournament otherwise. Forfeits, unrated games and byes are left
+// out.
+func (t *TRFTournament) Games() []*Game {
+	byRank := make(map[int]*TRFPlayer, len(t.Players))
+	for _, p := range t.Players {
+		byRank[p.StartRank] = p
+	}
+
+	date := t.End
+	if date.IsZero() {
+		date = t.Start
+	}
+
+	var games []*Game
+	for _, p := range t.Players {
+		for i, round := range p.Rounds {
+			opp, ok := byRank[round.Opponent]
+			if !round.Rated() || !ok {
+				continue
+			}
+			// Each game is in both players' records: take it from White's, or
+			// from the lower starting rank if colors weren't recorded.
+			if round.Color == 'b' || (round.Color != 'w' && p.StartRank > opp.StartRank) {
+				continue
+			}
+
+			g := &Game{
+				ID:      int64(len(games) + 1),
+				Player1: p.ID(),
+				Player2: opp.ID(),
+				Played:  date,
+				Weight:  1,
+			}
+			if i < len(t.RoundDates) && !t.RoundDates[i].IsZero() {
+				g.Played = t.RoundDates[i]
+			}
+			switch round.Result {
+			case '1':
+				g.Result = Win
+			case '0':
+				g.Result = Loss
+			default:
+				g.Result = Draw
+			}
+			games = append(games, g)
+		}
+	}
+
+	return games
+}
+
+// Write writes the report with the rating of every player in ratings replaced
+// by their rounded post-tournament rating. Everything else is written as read.
+func (t *TRFTournament) Write(w io.Writer, ratings map[string]*Rating) error {
+	lines := append([]string(nil), t.lines...)
+	for _, p := range t.Players {
+		r, ok := ratings[p.ID()]
+		if !ok {
+			continue
+		}
+
+		line := lines[p.line]
+		if len(line) < trfRatingEnd {
+			line += strings.Repeat(" ", trfRatingEnd-len(line))
+		}
+		rating := fmt.Sprintf("%4d", int(math.Round(r.rating)))
+		lines[p.line] = line[:trfRatingStart] + rating + line[trfRatingEnd:]
+	}
+
+	bw := bufio.NewWriter(w)
+	for _, line := range lines {
+		if _, err := bw.WriteString(line + "\n"); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
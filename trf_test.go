@@ -0,0 +1,86 @@
+package goglicko
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// trfPlayerLine formats a TRF player record; rounds are "opp c r" triples.
+func trfPlayerLine(rank int, name string, rating int, id string, points float64, rounds ...string) string {
+	line := fmt.Sprintf("001 %4d m    %-33s %4d RUS %11s 1990/01/01 %4.1f %4d",
+		rank, name, rating, id, points, rank)
+	for _, r := range rounds {
+		line += "  " + r
+	}
+	return line
+}
+
+func TestTRF(t *testing.T) {
+	in := strings.Join([]string{
+		"012 Club Open",
+		"042 2017/03/01",
+		"052 2017/03/03",
+		"132" + strings.Repeat(" ", 88) + "17/03/01  17/03/02",
+		trfPlayerLine(1, "Alpha, Anna", 1800, "1000", 1.5, "   2 w 1", "   3 b ="),
+		trfPlayerLine(2, "Beta, Bob", 1700, "", 1.0, "   1 b 0", "0000 - F"),
+		trfPlayerLine(3, "Gamma, Gus", 0, "3000", 0.5, "0000 - H", "   1 w ="),
+	}, "\n") + "\n"
+
+	tr, err := ReadTRF(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("Error while reading: %v", err)
+	}
+
+	t.Run("TestPlayers", func(t *testing.T) {
+		if len(tr.Players) != 3 {
+			t.Fatalf("Read %v players, expected 3", len(tr.Players))
+		}
+		p := tr.Players[0]
+		if p.StartRank != 1 || p.Name != "Alpha, Anna" || p.Rating != 1800 ||
+			p.ID() != "1000" || p.Points != 1.5 || len(p.Rounds) != 2 {
+			t.Errorf("First player read as %+v", p)
+		}
+		if r := p.Rounds[1]; r.Opponent != 3 || r.Color != 'b' || r.Result != '=' {
+			t.Errorf("Round read as %+v", r)
+		}
+		if tr.Players[1].ID() != "Beta, Bob" {
+			t.Errorf("Player without a FIDE ID has ID %v", tr.Players[1].ID())
+		}
+	})
+
+	t.Run("TestGames", func(t *testing.T) {
+		games := tr.Games()
+		if len(games) != 2 {
+			t.Fatalf("games %v, expected 2", games)
+		}
+		if g := games[0]; g.Player1 != "1000" || g.Player2 != "Beta, Bob" || g.Result != Win ||
+			!g.Played.Equal(time.Date(2017, time.March, 1, 0, 0, 0, 0, time.UTC)) {
+			t.Errorf("First game %+v", g)
+		}
+		if g := games[1]; g.Player1 != "3000" || g.Player2 != "1000" || g.Result != Draw {
+			t.Errorf("Second game %+v", g)
+		}
+	})
+
+	t.Run("TestWrite", func(t *testing.T) {
+		ratings := map[string]*Rating{"3000": NewRating(1612.6, 200, DefaultVol, NewDefaultSystem())}
+		var buf bytes.Buffer
+		if err := tr.Write(&buf, ratings); err != nil {
+			t.Fatalf("Error while writing: %v", err)
+		}
+
+		out, err := ReadTRF(&buf)
+		if err != nil {
+			t.Fatalf("Error while reading back: %v", err)
+		}
+		if out.Players[2].Rating != 1613 || out.Players[0].Rating != 1800 {
+			t.Errorf("Ratings written as %v and %v", out.Players[2].Rating, out.Players[0].Rating)
+		}
+		if out.Players[2].Rounds[1] != tr.Players[2].Rounds[1] {
+			t.Errorf("Rounds changed when writing")
+		}
+	})
+}